@@ -0,0 +1,348 @@
+package llamaparse
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// BatchInput is a file to parse with ParseBatch.
+type BatchInput struct {
+	File []byte
+	// FileName is the name the file is uploaded under, its extension tells LlamaParse the format of the file.
+	FileName string
+	// Priority orders the files of a batch, files with a higher one are started first. Files of the same priority are started in order.
+	Priority int
+}
+
+// BatchResult is the result of one file of a batch.
+type BatchResult struct {
+	// Index is the position of the file in the input.
+	Index   int
+	Content string
+	// Err is the error parsing this file failed with. A partially parsed file has both Content and Err.
+	Err error
+
+	// JobID and Metadata are empty for files that never got a job. Duplicates share them with the file they duplicate.
+	JobID    string
+	Metadata JobMetadata
+	// Started is when the file was handed to a worker, Duration how long it took from then.
+	Started  time.Time
+	Duration time.Duration
+}
+
+/*
+ParseBatch parses many files using the LlamaParse API, at most concurrency of them at a time.
+
+Args:
+
+	files: The files to parse.
+	mode: The output format (markdown, text, json).
+	concurrency: How many files are in progress at the same time, each from its upload until its result is fetched. Values below 1 are treated as 1.
+	To also cap the requests the batch shares with other calls on the client, use WithMaxConcurrency.
+	opts: Options for this batch only.
+
+Returns:
+
+	One result per file, in the order of files however they finish, so results[i] is always the one of files[i]. A file that fails doesn't stop the others, its error is in its result.
+	Files with the same contents and type are only uploaded once, their duplicates get a copy of the result.
+	The returned error is only set when no file could be parsed at all, e.g. without an API key.
+*/
+func (c *Client) ParseBatch(files []BatchInput, mode LlamaParseMode, concurrency int, opts ...Option) ([]BatchResult, error) {
+	return c.ParseBatchContext(context.Background(), files, mode, concurrency, opts...)
+}
+
+/*
+ParseBatchContext is ParseBatch with a context. Once ctx is done, files that haven't started yet aren't uploaded and the ones in progress stop.
+
+Args:
+
+	ctx: The context of every upload and status check of the batch.
+	The rest is the same as ParseBatch.
+
+Returns:
+
+	One result per file, in the order of files. Files stopped or never started because of ctx have ctx.Err() as their error.
+*/
+func (c *Client) ParseBatchContext(ctx context.Context, files []BatchInput, mode LlamaParseMode, concurrency int, opts ...Option) ([]BatchResult, error) {
+	c = c.with(opts)
+
+	err := c.check()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(files))
+	c.parseBatch(ctx, files, mode, concurrency, func(result BatchResult) {
+		results[result.Index] = result
+	})
+
+	return results, nil
+}
+
+/*
+ParseBatchStream is ParseBatch sending each result on the returned channel as soon as its file is parsed, instead of returning them all at the end.
+
+Args:
+
+	The same as ParseBatch.
+
+Returns:
+
+	A channel receiving one result per file in the order they finish, closed once all of them are sent. It is buffered to hold every result, so not reading it doesn't stop the batch.
+	The returned error is only set when no file could be parsed at all, e.g. without an API key.
+*/
+func (c *Client) ParseBatchStream(files []BatchInput, mode LlamaParseMode, concurrency int, opts ...Option) (<-chan BatchResult, error) {
+	return c.ParseBatchStreamContext(context.Background(), files, mode, concurrency, opts...)
+}
+
+/*
+ParseBatchStreamContext is ParseBatchStream with a context, which stops it the same way as ParseBatchContext.
+
+Args:
+
+	ctx: The context of every upload and status check of the batch.
+	The rest is the same as ParseBatch.
+
+Returns:
+
+	The same as ParseBatchStream.
+*/
+func (c *Client) ParseBatchStreamContext(ctx context.Context, files []BatchInput, mode LlamaParseMode, concurrency int, opts ...Option) (<-chan BatchResult, error) {
+	c = c.with(opts)
+
+	err := c.check()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan BatchResult, len(files))
+	go func() {
+		defer close(results)
+
+		c.parseBatch(ctx, files, mode, concurrency, func(result BatchResult) {
+			results <- result
+		})
+	}()
+
+	return results, nil
+}
+
+// parseBatch parses files, at most concurrency at a time, and calls emit with the result of each as soon as it is done.
+// emit is called from several goroutines, but never twice for the same index.
+func (c *Client) parseBatch(ctx context.Context, files []BatchInput, mode LlamaParseMode, concurrency int, emit func(BatchResult)) {
+	// duplicates are parsed once, under the index of their first occurrence
+	firsts := map[batchKey]int{}
+	duplicates := map[int][]int{}
+	var unique []int
+	for i, file := range files {
+		key := newBatchKey(file)
+		first, ok := firsts[key]
+		if !ok {
+			first = i
+			firsts[key] = i
+			unique = append(unique, i)
+		}
+		duplicates[first] = append(duplicates[first], i)
+	}
+
+	// files are handed out by priority, a file its duplicates share with one of a higher priority is parsed as early as that one
+	priorities := map[int]int{}
+	for _, first := range unique {
+		priority := files[first].Priority
+		for _, i := range duplicates[first] {
+			priority = max(priority, files[i].Priority)
+		}
+		priorities[first] = priority
+	}
+	slices.SortStableFunc(unique, func(a, b int) int {
+		return cmp.Compare(priorities[b], priorities[a])
+	})
+
+	emitAll := func(first int, result BatchResult) {
+		for _, i := range duplicates[first] {
+			result.Index = i
+			emit(result)
+		}
+	}
+
+	concurrency = max(1, min(concurrency, len(unique)))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				fc := c
+				if c.modeClassifier != nil {
+					fc = c.with(c.modeClassifier(files[i].FileName, detectMimeType(files[i].File, files[i].FileName)))
+				}
+
+				started := time.Now()
+				jobID, resultBytes, err := fc.parseFile(ctx, files[i].File, files[i].FileName, "", mode, nil)
+				content, err := fc.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+
+				// every mode's result carries the job metadata, a result without it just leaves it empty
+				var resultResponse struct {
+					JobMetadata JobMetadata `json:"job_metadata"`
+				}
+				json.Unmarshal(resultBytes, &resultResponse)
+
+				emitAll(i, BatchResult{
+					Content:  content,
+					Err:      err,
+					JobID:    jobID,
+					Metadata: resultResponse.JobMetadata,
+					Started:  started,
+					Duration: time.Since(started),
+				})
+			}
+		}()
+	}
+
+	// files not handed out yet when ctx is done are never uploaded
+	next := 0
+queue:
+	for ; next < len(unique); next++ {
+		select {
+		case indexes <- unique[next]:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	for _, i := range unique[next:] {
+		emitAll(i, BatchResult{Err: ctx.Err()})
+	}
+}
+
+// WithModeClassifier makes ParseBatch parse each file with the options classifier returns for its name and MIME type,
+// on top of the batch's own, e.g. WithPreset(PREMIUM) for scanned images and WithPreset(FAST) for born-digital PDFs.
+// Duplicates in a batch are parsed once, with the options of their first occurrence.
+func WithModeClassifier(classifier func(fileName string, mimeType string) []Option) Option {
+	return func(c *Client) {
+		c.modeClassifier = classifier
+	}
+}
+
+// batchKey tells files of a batch apart. Files with the same contents are only the same file if they are uploaded as the same type.
+type batchKey struct {
+	hash     [sha256.Size]byte
+	mimeType string
+}
+
+func newBatchKey(file BatchInput) batchKey {
+	return batchKey{
+		hash:     sha256.Sum256(file.File),
+		mimeType: detectMimeType(file.File, file.FileName),
+	}
+}
+
+// How many of the slowest files a BatchReport lists.
+const BATCH_REPORT_SLOWEST = 5
+
+// BatchReport sums up the results of a batch.
+type BatchReport struct {
+	Files     int
+	Succeeded int
+	// Partial counts the files that were only partly parsed, they aren't counted as failed.
+	Partial int
+	Failed  int
+	// FailuresByError counts the failed files by what they failed with, e.g. "job FAILED", "HTTP 500" or "timeout".
+	FailuresByError map[string]int
+
+	// Pages and Credits are summed over the jobs of the batch, duplicates sharing a job count once.
+	Pages   int
+	Credits float64
+	// WallTime is from the first file starting to the last one finishing.
+	WallTime time.Duration
+	// Slowest are the BATCH_REPORT_SLOWEST files that took the longest, slowest first.
+	Slowest []BatchResult
+}
+
+/*
+NewBatchReport sums up the results of a batch.
+
+Args:
+
+	results: The results of ParseBatch or ParseBatchContext, or the ones received from ParseBatchStream.
+
+Returns:
+
+	The report.
+*/
+func NewBatchReport(results []BatchResult) BatchReport {
+	report := BatchReport{
+		Files:           len(results),
+		FailuresByError: map[string]int{},
+	}
+
+	jobs := map[string]bool{}
+	var first, last time.Time
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			report.Succeeded++
+		case errors.Is(result.Err, ErrPartialSuccess):
+			report.Partial++
+		default:
+			report.Failed++
+			report.FailuresByError[batchErrorKind(result.Err)]++
+		}
+
+		if result.JobID != "" && !jobs[result.JobID] {
+			jobs[result.JobID] = true
+			report.Pages += result.Metadata.JobPages
+			report.Credits += result.Metadata.CreditsUsed
+		}
+
+		if result.Started.IsZero() {
+			continue
+		}
+		if first.IsZero() || result.Started.Before(first) {
+			first = result.Started
+		}
+		if finished := result.Started.Add(result.Duration); finished.After(last) {
+			last = finished
+		}
+	}
+	report.WallTime = last.Sub(first)
+
+	slowest := slices.Clone(results)
+	slices.SortStableFunc(slowest, func(a, b BatchResult) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	report.Slowest = slowest[:min(BATCH_REPORT_SLOWEST, len(slowest))]
+
+	return report
+}
+
+// batchErrorKind names what a file failed with, without the details that would make every failure its own kind.
+func batchErrorKind(err error) string {
+	var jobErr *JobError
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &jobErr):
+		return "job " + jobErr.Status
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("HTTP %d", apiErr.StatusCode)
+	case errors.Is(err, ErrTimeoutReached):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return err.Error()
+	}
+}
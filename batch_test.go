@@ -0,0 +1,159 @@
+package llamaparse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseBatchDeduplicates(t *testing.T) {
+	server := newFakeServer(t)
+
+	files := []BatchInput{
+		{File: []byte("same"), FileName: "a.txt"},
+		{File: []byte("other"), FileName: "b.txt"},
+		{File: []byte("same"), FileName: "c.txt"},
+		{File: []byte("same"), FileName: "d.txt"},
+	}
+
+	results, err := server.client().ParseBatch(files, MARKDOWN, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uploads := server.uploadCount(); uploads != 2 {
+		t.Errorf("got %d uploads, want 2", uploads)
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("file %d: %v", i, result.Err)
+		}
+		if result.Index != i {
+			t.Errorf("result %d has index %d", i, result.Index)
+		}
+		if result.Content != string(files[i].File) {
+			t.Errorf("file %d: got %q, want %q", i, result.Content, files[i].File)
+		}
+	}
+}
+
+func TestNewBatchReport(t *testing.T) {
+	server := newFakeServer(t)
+	server.status = func(job *fakeJob) string {
+		if job.file == "broken" {
+			return "ERROR"
+		}
+		return "SUCCESS"
+	}
+
+	files := []BatchInput{
+		{File: []byte("same"), FileName: "a.txt"},
+		{File: []byte("same"), FileName: "b.txt"},
+		{File: []byte("other"), FileName: "c.txt"},
+		{File: []byte("broken"), FileName: "d.txt"},
+	}
+
+	results, err := server.client().ParseBatch(files, MARKDOWN, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := NewBatchReport(results)
+	if report.Files != 4 || report.Succeeded != 3 || report.Failed != 1 || report.Partial != 0 {
+		t.Errorf("got %d files, %d succeeded, %d partial, %d failed, want 4, 3, 0, 1", report.Files, report.Succeeded, report.Partial, report.Failed)
+	}
+	if report.FailuresByError["job ERROR"] != 1 {
+		t.Errorf("got failures %v, want one job ERROR", report.FailuresByError)
+	}
+	// the duplicates share a job, its page is counted once
+	if report.Pages != 2 {
+		t.Errorf("got %d pages, want 2", report.Pages)
+	}
+	if len(report.Slowest) != 4 {
+		t.Errorf("got %d slowest, want 4", len(report.Slowest))
+	}
+	for i := 1; i < len(report.Slowest); i++ {
+		if report.Slowest[i].Duration > report.Slowest[i-1].Duration {
+			t.Errorf("slowest aren't sorted: %v before %v", report.Slowest[i-1].Duration, report.Slowest[i].Duration)
+		}
+	}
+	if report.WallTime <= 0 {
+		t.Errorf("got wall time %v", report.WallTime)
+	}
+}
+
+func TestParseBatchKeepsOrder(t *testing.T) {
+	server := newFakeServer(t)
+	// the first files take the most checks, so they finish last
+	server.status = func(job *fakeJob) string {
+		if job.checks < (5-len(job.file))*5 {
+			return "PENDING"
+		}
+		return "SUCCESS"
+	}
+
+	var files []BatchInput
+	for i := range 5 {
+		files = append(files, BatchInput{File: []byte(strings.Repeat("x", i+1)), FileName: "file.txt"})
+	}
+
+	results, err := server.client().ParseBatch(files, TEXT, len(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("file %d: %v", i, result.Err)
+		}
+		if result.Index != i || result.Content != string(files[i].File) {
+			t.Errorf("result %d: got index %d and %q, want %q", i, result.Index, result.Content, files[i].File)
+		}
+	}
+
+	stream, err := server.client().ParseBatchStream(files, TEXT, len(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var finished []int
+	for result := range stream {
+		finished = append(finished, result.Index)
+	}
+	if len(finished) != len(files) || finished[0] != len(files)-1 {
+		t.Errorf("got the stream in order %v, want the last file first", finished)
+	}
+}
+
+func TestParseBatchPriority(t *testing.T) {
+	server := newFakeServer(t)
+
+	files := []BatchInput{
+		{File: []byte("background 1"), FileName: "a.txt"},
+		{File: []byte("background 2"), FileName: "b.txt"},
+		{File: []byte("interactive"), FileName: "c.txt", Priority: 10},
+		{File: []byte("soon"), FileName: "d.txt", Priority: 1},
+		// shares the job of the first background file and raises its priority
+		{File: []byte("background 1"), FileName: "e.txt", Priority: 5},
+	}
+
+	results, err := server.client().ParseBatch(files, TEXT, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"interactive", "background 1", "soon", "background 2"}
+	for i, file := range want {
+		if got := server.jobs[fmt.Sprintf("job-%d", i+1)].file; got != file {
+			t.Errorf("upload %d: got %q, want %q", i+1, got, file)
+		}
+	}
+
+	// the results are still in the order of the files
+	for i, result := range results {
+		if result.Index != i || result.Content != string(files[i].File) {
+			t.Errorf("result %d: got index %d and %q", i, result.Index, result.Content)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package llamaparse
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type markdownBlock struct {
+	text string
+	// atomic blocks (tables and code) are never split, even if they are longer than a chunk
+	atomic bool
+}
+
+// markdownBlocks splits markdown on blank lines, keeping fenced code blocks whole.
+func markdownBlocks(text string) []markdownBlock {
+	var blocks []markdownBlock
+	var current []string
+	atomic := false
+	fence := ""
+
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, markdownBlock{text: strings.Join(current, "\n"), atomic: atomic})
+		}
+		current = nil
+		atomic = false
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			current = append(current, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			atomic = true
+			current = append(current, line)
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		if isTableRow(line) {
+			atomic = true
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return blocks
+}
+
+// overlapTail returns the trailing pieces of current that fit in overlap characters when joined by separatorLength characters, and their length.
+func overlapTail(current []string, separatorLength int, overlap int) ([]string, int) {
+	tail := len(current)
+	tailLength := 0
+	for tail > 0 {
+		length := utf8.RuneCountInString(current[tail-1])
+		if tailLength > 0 {
+			length += separatorLength
+		}
+		if tailLength+length > overlap {
+			break
+		}
+		tailLength += length
+		tail--
+	}
+
+	return slices.Clone(current[tail:]), tailLength
+}
+
+// splitWords splits text into pieces of at most size runes, breaking between words where possible.
+// Consecutive pieces share the trailing words that fit in overlap, a word longer than size is broken without overlap.
+func splitWords(text string, size int, overlap int) []string {
+	var pieces []string
+	var current []string
+	currentLength := 0
+
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > size {
+			if len(current) > 0 {
+				pieces = append(pieces, strings.Join(current, " "))
+				current = nil
+				currentLength = 0
+			}
+			runes := []rune(word)
+			pieces = append(pieces, string(runes[:size]))
+			word = string(runes[size:])
+		}
+
+		length := utf8.RuneCountInString(word)
+		if len(current) > 0 && currentLength+1+length > size {
+			pieces = append(pieces, strings.Join(current, " "))
+
+			current, currentLength = overlapTail(current, 1, overlap)
+			if len(current) > 0 && currentLength+1+length > size {
+				current = nil
+				currentLength = 0
+			}
+		}
+
+		if len(current) > 0 {
+			currentLength++
+		}
+		current = append(current, word)
+		currentLength += length
+	}
+
+	if len(current) > 0 {
+		pieces = append(pieces, strings.Join(current, " "))
+	}
+
+	return pieces
+}
+
+// splitLines splits text into pieces of at most size runes, breaking between lines so list items and table-like lines stay whole.
+// Only a single line longer than size is broken between words. Consecutive pieces share the trailing lines that fit in overlap.
+func splitLines(text string, size int, overlap int) []string {
+	var pieces []string
+	var current []string
+	currentLength := 0
+
+	for _, line := range strings.Split(text, "\n") {
+		length := utf8.RuneCountInString(line)
+
+		if length > size {
+			if len(current) > 0 {
+				pieces = append(pieces, strings.Join(current, "\n"))
+				current = nil
+				currentLength = 0
+			}
+			pieces = append(pieces, splitWords(line, size, overlap)...)
+			continue
+		}
+
+		if len(current) > 0 && currentLength+1+length > size {
+			pieces = append(pieces, strings.Join(current, "\n"))
+
+			current, currentLength = overlapTail(current, 1, overlap)
+			if len(current) > 0 && currentLength+1+length > size {
+				current = nil
+				currentLength = 0
+			}
+		}
+
+		if len(current) > 0 {
+			currentLength++
+		}
+		current = append(current, line)
+		currentLength += length
+	}
+
+	if len(current) > 0 {
+		pieces = append(pieces, strings.Join(current, "\n"))
+	}
+
+	return pieces
+}
+
+/*
+ChunkResult splits a markdown result into chunks of roughly size characters for embedding.
+
+Chunks are built from whole paragraphs where possible, longer paragraphs are split between lines and only a line longer than size between words. Tables and fenced code blocks are never split, so a chunk holding one can be longer than size.
+Consecutive chunks share up to overlap characters: the trailing paragraphs of the previous chunk, or the trailing lines or words of a paragraph split between them.
+
+Args:
+
+	text: The markdown or text result.
+	size: The maximum length of a chunk in characters.
+	overlap: How many characters from the end of a chunk to repeat at the start of the next one.
+
+Returns:
+
+	The chunks.
+*/
+func ChunkResult(text string, size int, overlap int) []string {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	if size <= 0 {
+		return []string{text}
+	}
+
+	var blocks []string
+	for _, block := range markdownBlocks(text) {
+		if block.atomic || utf8.RuneCountInString(block.text) <= size {
+			blocks = append(blocks, block.text)
+		} else {
+			blocks = append(blocks, splitLines(block.text, size, overlap)...)
+		}
+	}
+
+	var chunks []string
+	var current []string
+	currentLength := 0
+
+	for _, block := range blocks {
+		length := utf8.RuneCountInString(block)
+
+		if len(current) > 0 && currentLength+2+length > size {
+			chunks = append(chunks, strings.Join(current, "\n\n"))
+
+			// carry over the trailing blocks that fit in the overlap
+			current, currentLength = overlapTail(current, 2, overlap)
+			if len(current) > 0 && currentLength+2+length > size {
+				current = nil
+				currentLength = 0
+			}
+		}
+
+		if len(current) > 0 {
+			currentLength += 2
+		}
+		current = append(current, block)
+		currentLength += length
+	}
+
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+	}
+
+	return chunks
+}
+
+/*
+ParseChunks parses a file using the LlamaParse API and splits the result with ChunkResult.
+
+Args:
+
+	size, overlap: The same as in ChunkResult.
+	The rest is the same as Parse.
+
+Returns:
+
+	The chunks of the parsed file. If only part of it was parsed, the chunks of that part come with its *JobError wrapping ErrPartialSuccess.
+*/
+func ParseChunks(file []byte, mode LlamaParseMode, size int, overlap int, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) ([]string, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseChunks(context.Background(), file, mode, size, overlap, languageOptional)
+}
+
+/*
+ParseChunks parses a file and splits the result with ChunkResult.
+
+Args:
+
+	size, overlap: The same as in ChunkResult.
+	The rest is the same as Parse.
+
+Returns:
+
+	The chunks of the parsed file. If only part of it was parsed, the chunks of that part come with its *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseChunks(file []byte, mode LlamaParseMode, size int, overlap int, opts ...Option) ([]string, error) {
+	return c.with(opts).parseChunks(context.Background(), file, mode, size, overlap, nil)
+}
+
+func (c *Client) parseChunks(ctx context.Context, file []byte, mode LlamaParseMode, size int, overlap int, language *string) ([]string, error) {
+	result, err := c.parseContext(ctx, file, mode, language)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	return ChunkResult(result, size, overlap), err
+}
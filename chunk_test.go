@@ -0,0 +1,117 @@
+package llamaparse
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkResult(t *testing.T) {
+	table := "| a | b |\n|---|---|\n| 1 | 2 |\n| 3 | 4 |"
+
+	tests := []struct {
+		name    string
+		text    string
+		size    int
+		overlap int
+		want    []string
+	}{
+		{
+			name: "empty",
+			text: " \n\n ",
+			size: 10,
+		},
+		{
+			name: "no size",
+			text: "one\n\ntwo",
+			want: []string{"one\n\ntwo"},
+		},
+		{
+			name: "paragraphs",
+			text: "first paragraph\n\nsecond paragraph\n\nthird",
+			size: 35,
+			want: []string{"first paragraph\n\nsecond paragraph", "third"},
+		},
+		{
+			name: "separator counts",
+			// the two paragraphs and the blank line between them are 21 characters
+			text: "0123456789\n\n012345678",
+			size: 20,
+			want: []string{"0123456789", "012345678"},
+		},
+		{
+			name: "list split between items",
+			text: "# Title\n\n- item one is here\n- item two is here\n- item three is here\n- item four is here",
+			size: 40,
+			want: []string{"# Title", "- item one is here\n- item two is here", "- item three is here\n- item four is here"},
+		},
+		{
+			name: "long line split between words",
+			text: "alpha beta gamma delta epsilon",
+			size: 12,
+			want: []string{"alpha beta", "gamma delta", "epsilon"},
+		},
+		{
+			name: "table kept whole",
+			text: "intro\n\n" + table + "\n\noutro",
+			size: 10,
+			want: []string{"intro", table, "outro"},
+		},
+		{
+			name: "code kept whole",
+			text: "```go\nfunc main() {\n\tprintln(\"hi\")\n}\n```",
+			size: 10,
+			want: []string{"```go\nfunc main() {\n\tprintln(\"hi\")\n}\n```"},
+		},
+		{
+			name:    "overlap",
+			text:    "aaaa\n\nbbbb\n\ncccc\n\ndddd",
+			size:    10,
+			overlap: 4,
+			want:    []string{"aaaa\n\nbbbb", "bbbb\n\ncccc", "cccc\n\ndddd"},
+		},
+		{
+			name:    "overlap that doesn't fit",
+			text:    "aaaa\n\nbbbb\n\ncccccccc",
+			size:    10,
+			overlap: 4,
+			want:    []string{"aaaa\n\nbbbb", "cccccccc"},
+		},
+		{
+			name:    "long line overlapping between words",
+			text:    "one two three four five six seven eight nine ten",
+			size:    10,
+			overlap: 4,
+			want:    []string{"one two", "two three", "four five", "five six", "six seven", "eight nine", "nine ten"},
+		},
+		{
+			name:    "long paragraph overlapping between lines",
+			text:    "- aaa\n- bbb\n- ccc\n- ddd",
+			size:    11,
+			overlap: 5,
+			want:    []string{"- aaa\n- bbb", "- bbb\n- ccc", "- ccc\n- ddd"},
+		},
+		{
+			name: "runes",
+			text: "żółw żółw\n\nżółw",
+			size: 10,
+			want: []string{"żółw żółw", "żółw"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ChunkResult(test.text, test.size, test.overlap)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+
+			for _, chunk := range got {
+				if test.size > 0 && utf8.RuneCountInString(chunk) > test.size && !strings.Contains(chunk, "|") && !strings.Contains(chunk, "```") {
+					t.Errorf("chunk %q is longer than %d", chunk, test.size)
+				}
+			}
+		})
+	}
+}
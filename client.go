@@ -0,0 +1,1096 @@
+package llamaparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client holds the configuration shared by every call made through it, so one configured client can be reused across many parses.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	paths      Paths
+	httpClient *http.Client
+	// credentialProvider replaces apiKey when set, it is asked for the key before every request.
+	credentialProvider func(ctx context.Context) (string, error)
+	requestDecorators  []func(*http.Request) error
+	disableKeepAlives  bool
+	requestID          string
+	recorder           *recorder
+	// requestSlots limits the requests in flight across every call made through the client and its copies, nil doesn't limit them.
+	requestSlots chan struct{}
+	// timeout is the limit of a whole parse, requestTimeout the limit of each request made during it.
+	timeout        time.Duration
+	requestTimeout time.Duration
+	// deadline is an absolute limit on top of timeout, zero for none.
+	deadline      time.Time
+	checkInterval time.Duration
+	initialDelay  time.Duration
+	// resultGracePeriod is waited between a job finishing and fetching its result.
+	resultGracePeriod time.Duration
+	// the default poll strategy grows the wait from checkInterval by checkFactor up to maxCheckInterval
+	maxCheckInterval time.Duration
+	checkFactor      float64
+	pollStrategy     PollStrategy
+	onProgress       func(Progress)
+	onUploadProgress func(sent int64, total int64)
+	maxRetries       int
+	// maxResultSize is the maximum number of bytes read from a result body, 0 disables the limit.
+	maxResultSize int64
+	// maxZipEntrySize is the maximum number of bytes extracted from an entry of a ZIP archive, 0 disables the limit.
+	maxZipEntrySize  int64
+	uploadBufferSize int
+	// multipartBoundary is the boundary of upload bodies, empty for a random one.
+	multipartBoundary string
+
+	// fileName is the name the file is uploaded under.
+	fileName string
+
+	parsingInstruction string
+	systemPrompt       string
+	userPrompt         string
+	preset             ParsePreset
+	// presetSet is reset before every set of options, so a preset only conflicts with one passed next to it.
+	presetSet   bool
+	autoMode    *AutoModeTriggers
+	targetPages string
+	languages   []string
+	webhookURL  string
+	// takeScreenshot adds a rendering of every page to its images.
+	takeScreenshot bool
+	tablesAsHTML   bool
+	// inlineImages replaces the image links of markdown results with the images themselves.
+	inlineImages bool
+	doNotCache   bool
+	// inputConverter converts content ParseString is given in a MIME type LlamaParse doesn't support.
+	inputConverter func(file []byte, mimeType string) ([]byte, string, error)
+	// validateCached tells whether a result served from the cache is still right, ParseDetailed parses again without the cache if it isn't.
+	validateCached func(result *Result) bool
+
+	// mergeTables post-processes markdown results with MergeTables, stripRepeatedHeaders markdown and text results with StripRepeatedHeaders.
+	mergeTables          bool
+	stripRepeatedHeaders bool
+
+	// strict fails partially parsed files instead of returning the parsed part.
+	strict bool
+
+	modeClassifier  func(fileName string, mimeType string) []Option
+	mimeAutoCorrect bool
+	allowEmptyFile  bool
+	// fallback are the options a file is parsed again with when its job fails.
+	fallback []Option
+
+	// err is the first invalid option, it is returned by every call instead of sending anything.
+	err error
+}
+
+// Option configures a Client created by New. Options passed to a single call only apply to that call.
+type Option func(*Client)
+
+/*
+New creates a LlamaParse client.
+
+Args:
+
+	apiKey: The LlamaCloud API key. If empty, it will be read from the LLAMA_CLOUD_API_KEY environment variable.
+	opts: Options that change the client's defaults.
+
+Returns:
+
+	The client. A missing API key is reported by the first call made with it, as ErrNoAPIKey.
+*/
+func New(apiKey string, opts ...Option) *Client {
+	// Keys are trimmed, a stray newline from a secrets file would otherwise end up as an opaque 401.
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		apiKey = strings.TrimSpace(os.Getenv("LLAMA_CLOUD_API_KEY"))
+	}
+
+	c := &Client{
+		apiKey:           apiKey,
+		baseURL:          BASE_URL,
+		paths:            Paths{UPLOAD_PATH, JOB_STATUS_PATH, JOB_RESULT_PATH, JOB_IMAGE_PATH},
+		httpClient:       &http.Client{Transport: transport},
+		timeout:          DEFAULT_MAX_TIMEOUT_SECONDS * time.Second,
+		requestTimeout:   DEFAULT_REQUEST_TIMEOUT_SECONDS * time.Second,
+		checkInterval:    DEFAULT_CHECK_INTERVAL_SECONDS * time.Second,
+		maxCheckInterval: DEFAULT_MAX_CHECK_INTERVAL_SECONDS * time.Second,
+		checkFactor:      DEFAULT_CHECK_FACTOR,
+		maxRetries:       DEFAULT_MAX_RETRIES,
+		maxResultSize:    DEFAULT_MAX_RESULT_SIZE_BYTES,
+		maxZipEntrySize:  DEFAULT_MAX_ZIP_ENTRY_SIZE_BYTES,
+		uploadBufferSize: DEFAULT_UPLOAD_BUFFER_SIZE_BYTES,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithBaseURL makes the client talk to another LlamaCloud region or a gateway, e.g. https://api.cloud.eu.llamaindex.ai.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// Paths are the paths of the API endpoints, relative to the base URL. The job paths are format strings, see JOB_STATUS_PATH and the constants next to it.
+// Each has one %s per value it is given, in that order, and a literal % written as %%.
+type Paths struct {
+	Upload    string
+	JobStatus string // job ID
+	JobResult string // job ID, mode
+	JobImage  string // job ID, image name
+}
+
+// WithPaths makes the client use the endpoint paths of a gateway that remaps them. Empty fields keep the current path.
+// A job path without exactly one %s per value, or with another verb, makes the client fail with ErrInvalidPaths.
+func WithPaths(paths Paths) Option {
+	return func(c *Client) {
+		for _, path := range []struct {
+			template string
+			values   int
+		}{{paths.JobStatus, 1}, {paths.JobResult, 2}, {paths.JobImage, 2}} {
+			if path.template != "" && !validPathTemplate(path.template, path.values) {
+				c.err = fmt.Errorf("%w: %q", ErrInvalidPaths, path.template)
+				return
+			}
+		}
+
+		if paths.Upload != "" {
+			c.paths.Upload = paths.Upload
+		}
+		if paths.JobStatus != "" {
+			c.paths.JobStatus = paths.JobStatus
+		}
+		if paths.JobResult != "" {
+			c.paths.JobResult = paths.JobResult
+		}
+		if paths.JobImage != "" {
+			c.paths.JobImage = paths.JobImage
+		}
+	}
+}
+
+// validPathTemplate reports whether the only verbs of template are values times %s, besides %% for a literal %.
+func validPathTemplate(template string, values int) bool {
+	verbs := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		if i+1 >= len(template) {
+			return false
+		}
+
+		i++
+		switch template[i] {
+		case '%':
+		case 's':
+			verbs++
+		default:
+			return false
+		}
+	}
+
+	return verbs == values
+}
+
+// WithCredentialProvider makes the client ask provider for the API key before every request instead of using the one passed to New,
+// so a key rotated by a secrets manager is picked up without creating a new client. An error from provider fails the request with it.
+func WithCredentialProvider(provider func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.credentialProvider = provider
+	}
+}
+
+// WithDisableKeepAlives makes the client close every connection once its response is read instead of keeping it for the next request.
+// Short-lived programs such as CLIs then have no idle connections left to wait for when they exit. It works with the transport of WithHTTPClient too.
+func WithDisableKeepAlives(disable bool) Option {
+	return func(c *Client) {
+		c.disableKeepAlives = disable
+	}
+}
+
+// WithRequestID sends requestID in the REQUEST_ID_HEADER of every request, so a parse can be traced across services.
+// It is usually passed to a single call. An *APIError reports the ID of the request it failed on.
+func WithRequestID(requestID string) Option {
+	return func(c *Client) {
+		c.requestID = requestID
+	}
+}
+
+// WithMaxConcurrency limits the client to maxConcurrency requests in flight at a time, shared by every call made through it,
+// so a large batch can't take all connections from interactive calls. A request holds its slot until its response body is closed.
+// It is meant to be passed to New, passed to a single call it only limits that call. Values below 1 remove the limit.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(c *Client) {
+		if maxConcurrency < 1 {
+			c.requestSlots = nil
+			return
+		}
+
+		c.requestSlots = make(chan struct{}, maxConcurrency)
+	}
+}
+
+// WithRequestDecorator makes the client call decorator on every request right before it is sent, including each retry, e.g. to sign it or add headers.
+// Decorators run in the order they were added. An error from one fails the request with it.
+func WithRequestDecorator(decorator func(*http.Request) error) Option {
+	return func(c *Client) {
+		// clipped so a decorator passed to one call doesn't end up in the client's own slice
+		c.requestDecorators = append(slices.Clip(c.requestDecorators), decorator)
+	}
+}
+
+// WithHTTPClient makes the client send its requests with httpClient, keeping its transport, proxy and TLS configuration.
+// Its Timeout is still capped to the one set by WithRequestTimeout. A nil httpClient keeps the default one.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTimeout sets the maximum time a whole parse may take, from the start of the upload to fetching the result. Default is 2000 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithDeadline sets a time by which a whole parse, or a wait for a job, has to be done, e.g. the deadline of the request it serves.
+// It bounds the upload and the polling along with WithTimeout and the deadline of the context, whichever comes first. Running into it fails with ErrTimeoutReached.
+// A zero deadline removes it.
+func WithDeadline(deadline time.Time) Option {
+	return func(c *Client) {
+		c.deadline = deadline
+	}
+}
+
+// WithInputConverter makes ParseString convert content of a MIME type that isn't in SUPPORTED_MIME_TYPES with convert instead of failing with ErrUnsupportedMimeType,
+// e.g. to wrap markdown as text/plain. convert returns the converted file and its MIME type, which has to be supported. Its error is returned as is.
+func WithInputConverter(convert func(file []byte, mimeType string) ([]byte, string, error)) Option {
+	return func(c *Client) {
+		c.inputConverter = convert
+	}
+}
+
+// WithDoNotCache makes LlamaParse parse the file again instead of serving a result it cached for the same file, which is billed as usual.
+func WithDoNotCache(doNotCache bool) Option {
+	return func(c *Client) {
+		c.doNotCache = doNotCache
+	}
+}
+
+// WithValidateCached makes ParseDetailed check a result served from the LlamaParse cache with validate,
+// and parse the file again WithDoNotCache if validate reports it stale, e.g. because it lacks a section the file is known to have.
+// Results that weren't cached aren't checked. A nil validate disables the check.
+func WithValidateCached(validate func(result *Result) bool) Option {
+	return func(c *Client) {
+		c.validateCached = validate
+	}
+}
+
+// WithRequestTimeout sets the maximum time of a single HTTP request, e.g. the upload of a large file over a slow link. Default is 120 seconds.
+// Requests are still cut short when the timeout of the whole parse is reached.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithCheckInterval sets the interval before the first check of the parsing status. Default is 1 second.
+// The interval grows by the check factor after every check, up to the max check interval.
+func WithCheckInterval(checkInterval time.Duration) Option {
+	return func(c *Client) {
+		c.checkInterval = checkInterval
+	}
+}
+
+// WithInitialDelay waits at least initialDelay before the first check of the parsing status, whatever the poll strategy,
+// for documents known to take long to parse. The checks after it keep their usual interval.
+func WithInitialDelay(initialDelay time.Duration) Option {
+	return func(c *Client) {
+		c.initialDelay = initialDelay
+	}
+}
+
+// WithResultGracePeriod waits gracePeriod after a job reports it succeeded before fetching its result, for when the result becomes
+// available later than the status says. A result that still isn't there is retried RESULT_FETCH_RETRIES times either way.
+func WithResultGracePeriod(gracePeriod time.Duration) Option {
+	return func(c *Client) {
+		c.resultGracePeriod = gracePeriod
+	}
+}
+
+// WithMaxCheckInterval caps the interval between checks of the parsing status. Default is 10 seconds.
+// Set it to the check interval to poll at a fixed interval.
+func WithMaxCheckInterval(maxCheckInterval time.Duration) Option {
+	return func(c *Client) {
+		c.maxCheckInterval = maxCheckInterval
+	}
+}
+
+// WithCheckFactor sets how much the interval between checks of the parsing status grows after each check. Default is 2.
+func WithCheckFactor(factor float64) Option {
+	return func(c *Client) {
+		c.checkFactor = factor
+	}
+}
+
+// WithPollStrategy replaces how the parsing status is polled, the check interval options are ignored then.
+func WithPollStrategy(poll PollStrategy) Option {
+	return func(c *Client) {
+		c.pollStrategy = poll
+	}
+}
+
+// WithProgress calls onProgress after every status check of a job, e.g. to render a spinner.
+// It is called from the polling loop, so it has to return quickly or hand the progress off to another goroutine.
+func WithProgress(onProgress func(Progress)) Option {
+	return func(c *Client) {
+		c.onProgress = onProgress
+	}
+}
+
+// poll returns the strategy jobs are polled with.
+func (c *Client) poll() PollStrategy {
+	if c.pollStrategy != nil {
+		return c.pollStrategy
+	}
+
+	return ExponentialBackoff{
+		Initial: c.checkInterval,
+		// a check interval above the cap is kept as is rather than lowered
+		Max:    max(c.maxCheckInterval, c.checkInterval),
+		Factor: c.checkFactor,
+	}
+}
+
+// WithMaxRetries sets how many times a request answered with 429 Too Many Requests or a 5xx is retried. Default is 5, 0 disables retrying.
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// WithMaxResultSize limits how many bytes of a result are read, results above it fail with ErrResultTooLarge. 0 disables the limit.
+func WithMaxResultSize(maxBytes int64) Option {
+	return func(c *Client) {
+		c.maxResultSize = maxBytes
+	}
+}
+
+// WithUploadBufferSize sets the size of the buffer files are copied into the upload with. Values below 1 keep the current size.
+func WithUploadBufferSize(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.uploadBufferSize = size
+		}
+	}
+}
+
+// WithMultipartBoundary makes uploads use boundary instead of a random one, for proxies or recorded fixtures that expect a fixed body.
+// The boundary must not appear in the file. An empty boundary goes back to a random one.
+func WithMultipartBoundary(boundary string) Option {
+	return func(c *Client) {
+		if boundary != "" && multipart.NewWriter(io.Discard).SetBoundary(boundary) != nil {
+			c.err = fmt.Errorf("%w: %q", ErrInvalidBoundary, boundary)
+			return
+		}
+		c.multipartBoundary = boundary
+	}
+}
+
+// WithAllowEmptyFile uploads empty files instead of failing with ErrEmptyFile before sending anything, for servers that handle them.
+func WithAllowEmptyFile(allow bool) Option {
+	return func(c *Client) {
+		c.allowEmptyFile = allow
+	}
+}
+
+// WithFilename uploads the file under fileName, its extension tells LlamaParse the format of the file.
+// It also determines the Content-Type of the upload when it has a known extension, instead of detecting it from the contents.
+func WithFilename(fileName string) Option {
+	return func(c *Client) {
+		c.fileName = fileName
+	}
+}
+
+// WithParsingInstruction tells the model parsing the file what it is looking at, e.g. "This is a scientific paper, preserve equations as LaTeX".
+func WithParsingInstruction(instruction string) Option {
+	return func(c *Client) {
+		c.parsingInstruction = instruction
+	}
+}
+
+// WithSystemPrompt replaces the system prompt of the model parsing the file.
+func WithSystemPrompt(prompt string) Option {
+	return func(c *Client) {
+		c.systemPrompt = prompt
+	}
+}
+
+// WithUserPrompt sets the user prompt of the model parsing the file, appended to its system prompt.
+func WithUserPrompt(prompt string) Option {
+	return func(c *Client) {
+		c.userPrompt = prompt
+	}
+}
+
+// WithWebhookURL makes LlamaParse call webhookURL once the job finishes.
+// It is meant for SubmitJob, which never polls: the caller has to fetch the result with GetResult once notified.
+// Parse and the other blocking calls still poll, with the webhook being called in addition.
+func WithWebhookURL(webhookURL string) Option {
+	return func(c *Client) {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			c.err = fmt.Errorf("%w: %q", ErrInvalidWebhookURL, webhookURL)
+			return
+		}
+
+		c.webhookURL = webhookURL
+	}
+}
+
+// WithStrict makes a partially parsed file fail like one that wasn't parsed at all, with a *JobError wrapping ErrParsingFailed
+// whose Status is PARTIAL_SUCCESS, instead of returning the parsed part. Nothing is returned that is missing pages.
+func WithStrict(strict bool) Option {
+	return func(c *Client) {
+		c.strict = strict
+	}
+}
+
+// WithFallback makes a file whose job fails with ERROR or FAILED be parsed once more with opts applied on top of the call's options,
+// e.g. WithPreset(FAST) when premium parsing fails on a document. ParseDetailed reports it in Result.Fallback.
+// Only files held in memory are parsed again, a streamed file can't be read twice.
+func WithFallback(opts ...Option) Option {
+	return func(c *Client) {
+		c.fallback = opts
+	}
+}
+
+// with returns a copy of the client with opts applied, the client itself is left as is.
+func (c *Client) with(opts []Option) *Client {
+	if len(opts) == 0 {
+		return c
+	}
+
+	copied := *c
+	copied.presetSet = false
+	for _, opt := range opts {
+		opt(&copied)
+	}
+
+	return &copied
+}
+
+// clientFromOptional builds the client the package-level functions run on out of their optional arguments.
+func clientFromOptional(apiKeyOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) *Client {
+	c := New("")
+	if apiKeyOptional != nil {
+		// an explicitly passed key never falls back to the environment
+		c.apiKey = strings.TrimSpace(*apiKeyOptional)
+	}
+
+	c.timeout, c.checkInterval = resolveTimeouts(timeoutSecondsOptional, checkIntervalSecondsOptional)
+
+	return c
+}
+
+// formFields returns the form fields the upload carries besides the file.
+// language is the single language of the package-level functions, it is sent before the client's languages.
+func (c *Client) formFields(language *string) []formField {
+	var fields []formField
+
+	if language != nil {
+		fields = append(fields, formField{"language", *language})
+	}
+	for _, language := range c.languages {
+		fields = append(fields, formField{"language", language})
+	}
+	if c.parsingInstruction != "" {
+		fields = append(fields, formField{"parsing_instruction", c.parsingInstruction})
+	}
+	if c.systemPrompt != "" {
+		fields = append(fields, formField{"system_prompt", c.systemPrompt})
+	}
+	if c.userPrompt != "" {
+		fields = append(fields, formField{"user_prompt", c.userPrompt})
+	}
+	fields = append(fields, presetFields(c.preset)...)
+	fields = append(fields, autoModeFields(c.autoMode)...)
+	if c.targetPages != "" {
+		fields = append(fields, formField{"target_pages", c.targetPages})
+	}
+	if c.webhookURL != "" {
+		fields = append(fields, formField{"webhook_url", c.webhookURL})
+	}
+	if c.takeScreenshot {
+		fields = append(fields, formField{"take_screenshot", "true"})
+	}
+	if c.doNotCache {
+		fields = append(fields, formField{"do_not_cache", "true"})
+	}
+	if c.tablesAsHTML {
+		fields = append(fields, formField{"output_tables_as_HTML", "true"})
+	}
+
+	return fields
+}
+
+// check reports an invalid option or a missing API key before anything is sent.
+func (c *Client) check() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if c.apiKey == "" && c.credentialProvider == nil {
+		return ErrNoAPIKey
+	}
+
+	return nil
+}
+
+// newRequest creates an authenticated request to the API endpoint at path.
+func (c *Client) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	apiKey := c.apiKey
+	if c.credentialProvider != nil {
+		var err error
+		apiKey, err = c.credentialProvider(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		apiKey = strings.TrimSpace(apiKey)
+		if apiKey == "" {
+			return nil, ErrNoAPIKey
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if c.requestID != "" {
+		req.Header.Set(REQUEST_ID_HEADER, c.requestID)
+	}
+
+	return req, nil
+}
+
+// do sends req, limiting it to the timeout of a single request. If the request's context is done, its error is returned instead of the transport's.
+// Responses with a retryable status are retried, unless the body of req can't be sent again or the wait would pass the deadline of its context.
+// Once the retries run out, the last response is returned.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	client := *c.httpClient
+	if client.Timeout == 0 || client.Timeout > c.requestTimeout {
+		client.Timeout = c.requestTimeout
+	}
+
+	ctx := req.Context()
+	if c.disableKeepAlives {
+		req.Close = true
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.prepare(req)
+		if err != nil {
+			// client.Do closes the body of a request it sends, this one is never sent
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+
+		resp, err := c.send(&client, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		if c.recorder != nil {
+			err = c.recorder.recordResponse(resp)
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+		}
+
+		// a streamed body is gone once it was sent
+		replayable := req.Body == nil || req.GetBody != nil
+		if !retryableStatus(resp.StatusCode) || attempt >= c.maxRetries || !replayable {
+			return resp, nil
+		}
+
+		wait := retryDelay(resp, attempt)
+		deadline, ok := ctx.Deadline()
+		if ok && time.Until(deadline) < wait {
+			return resp, nil
+		}
+		if !ok {
+			// without a deadline nothing else bounds a Retry-After of hours
+			wait = min(wait, RETRY_MAX_DELAY)
+		}
+		resp.Body.Close()
+
+		err = sleep(ctx, wait)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// send sends req with client once a request slot is free. The slot is freed when the response body is closed.
+func (c *Client) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	if c.requestSlots == nil {
+		return client.Do(req)
+	}
+
+	select {
+	case c.requestSlots <- struct{}{}:
+	case <-req.Context().Done():
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, req.Context().Err()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		<-c.requestSlots
+		return nil, err
+	}
+
+	resp.Body = &slotBody{ReadCloser: resp.Body, slots: c.requestSlots}
+	return resp, nil
+}
+
+// slotBody frees its request slot when closed.
+type slotBody struct {
+	io.ReadCloser
+	slots chan struct{}
+	once  sync.Once
+}
+
+func (b *slotBody) Close() error {
+	b.once.Do(func() {
+		<-b.slots
+	})
+
+	return b.ReadCloser.Close()
+}
+
+// prepare runs the request decorators on req and records it, right before it is sent.
+func (c *Client) prepare(req *http.Request) error {
+	for _, decorate := range c.requestDecorators {
+		err := decorate(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.recorder != nil {
+		return c.recorder.recordRequest(req)
+	}
+
+	return nil
+}
+
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryDelay is how long to wait before retrying resp. Retry-After is honored, in seconds or as a date,
+// without it the delay grows exponentially with the attempt and is jittered so parallel callers don't retry in lockstep.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	retryAfter := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	// the shift is clamped so a large WithMaxRetries can't overflow it, RETRY_MAX_DELAY is reached long before
+	delay := min(RETRY_INITIAL_DELAY<<min(attempt, 16), RETRY_MAX_DELAY)
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+/*
+Parse parses a file using the LlamaParse API.
+
+Args:
+
+	file: The file to parse.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only, e.g. WithFilename.
+
+Returns:
+
+	The parsed file. In JSON mode it is the whole JSON result document, which DecodeResult can decode.
+	A job that failed returns a *JobError. If only part of the file was parsed, the parsed part is returned along with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) Parse(file []byte, mode LlamaParseMode, opts ...Option) (string, error) {
+	return c.ParseContext(context.Background(), file, mode, opts...)
+}
+
+/*
+ParseContext is Parse with a context. Cancelling ctx stops the upload or the polling and returns ctx.Err().
+
+Args:
+
+	ctx: The context of the upload and every status check.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed file.
+*/
+func (c *Client) ParseContext(ctx context.Context, file []byte, mode LlamaParseMode, opts ...Option) (string, error) {
+	return c.with(opts).parseContext(ctx, file, mode, nil)
+}
+
+func (c *Client) parseContext(ctx context.Context, file []byte, mode LlamaParseMode, language *string) (string, error) {
+	jobID, resultBytes, err := c.parseFile(ctx, file, c.fileName, "", mode, language)
+	return c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+}
+
+/*
+ParseFile parses the file at path using the LlamaParse API. The file is streamed into the upload, so it is never held in memory as a whole, and read again from the start if the upload is retried.
+
+Args:
+
+	path: The path of the file. Its name is passed on to LlamaParse and its extension determines the MIME type.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed file.
+*/
+func (c *Client) ParseFile(path string, mode LlamaParseMode, opts ...Option) (string, error) {
+	c = c.with(opts)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() == 0 && !c.allowEmptyFile {
+		return "", ErrEmptyFile
+	}
+
+	jobID, resultBytes, err := c.parseReader(context.Background(), file, filepath.Base(path), mimeTypeFromName(path), mode, nil)
+	return c.decodeJobResult(context.Background(), jobID, resultBytes, mode, err)
+}
+
+/*
+ParseReader parses a file read from r, such as an http.Request body or a pipe, using the LlamaParse API.
+
+r is streamed into the upload as it is read. Unless r is an io.Seeker, it can't be sent again, so the upload isn't retried on 429 or 5xx.
+
+Args:
+
+	r: The file to parse.
+	fileName: The name of the file. Its extension determines the MIME type.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed file.
+*/
+func (c *Client) ParseReader(r io.Reader, fileName string, mode LlamaParseMode, opts ...Option) (string, error) {
+	c = c.with(opts)
+
+	jobID, resultBytes, err := c.parseReader(context.Background(), r, fileName, mimeTypeFromName(fileName), mode, nil)
+	return c.decodeJobResult(context.Background(), jobID, resultBytes, mode, err)
+}
+
+/*
+ParseDetailed is Parse returning the result along with its job ID, status, page count and credits used.
+
+Args:
+
+	The same as Parse.
+
+Returns:
+
+	The parsed file and the job details. If only part of the file was parsed, the result comes with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseDetailed(file []byte, mode LlamaParseMode, opts ...Option) (*Result, error) {
+	return c.with(opts).parseDetailed(context.Background(), file, mode, nil)
+}
+
+/*
+ParseJSON parses a file in JSON mode and returns its pages.
+
+Args:
+
+	file: The file to parse.
+	opts: Options for this call only.
+
+Returns:
+
+	The pages of the file with their text, markdown, images and layout items.
+	If only part of the file was parsed, the parsed pages come with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseJSON(file []byte, opts ...Option) ([]Page, error) {
+	c = c.with(opts)
+
+	_, resultBytes, err := c.parseFile(context.Background(), file, c.fileName, "", JSON, nil)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	result, decodeErr := DecodeResult(resultBytes)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return result.Pages, err
+}
+
+/*
+ParseMulti parses a file once and fetches its result in several output formats, so each format doesn't cost another parse.
+
+Args:
+
+	file: The file to parse.
+	modes: The output formats to fetch.
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed file in each of modes. If only part of the file was parsed, the results come with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseMulti(file []byte, modes []LlamaParseMode, opts ...Option) (map[LlamaParseMode]string, error) {
+	return c.with(opts).parseMulti(context.Background(), file, modes, nil)
+}
+
+func (c *Client) parseMulti(ctx context.Context, file []byte, modes []LlamaParseMode, language *string) (map[LlamaParseMode]string, error) {
+	if len(modes) == 0 {
+		return map[LlamaParseMode]string{}, nil
+	}
+
+	jobID, resultBytes, jobErr := c.parseFile(ctx, file, c.fileName, "", modes[0], language)
+	content, jobErr := c.decodeJobResult(ctx, jobID, resultBytes, modes[0], jobErr)
+	if jobErr != nil && !errors.Is(jobErr, ErrPartialSuccess) {
+		return nil, jobErr
+	}
+
+	results := map[LlamaParseMode]string{modes[0]: content}
+
+	// the job is done by now, so the other results only have to be fetched, a few at a time
+	var mutex sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	fetching := make(chan struct{}, RESULT_FETCH_CONCURRENCY)
+	started := map[LlamaParseMode]bool{modes[0]: true}
+	for _, mode := range modes[1:] {
+		if started[mode] {
+			continue
+		}
+		started[mode] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			fetching <- struct{}{}
+			defer func() { <-fetching }()
+
+			resultBytes, err := c.getResultBytes(ctx, jobID, mode)
+			content, err := c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", mode, err))
+				return
+			}
+			results[mode] = content
+		}()
+	}
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, jobErr
+}
+
+/*
+ParseBytes is Parse returning the raw body of the result endpoint, undecoded.
+
+Args:
+
+	The same as Parse.
+
+Returns:
+
+	The raw JSON result document, e.g. {"markdown": "...", "job_metadata": {...}} in markdown mode.
+*/
+func (c *Client) ParseBytes(file []byte, mode LlamaParseMode, opts ...Option) ([]byte, error) {
+	return c.with(opts).parseBytes(context.Background(), file, mode, nil)
+}
+
+/*
+ParseString parses in-memory content, such as scraped HTML.
+
+Args:
+
+	content: The content to parse.
+	mimeType: The MIME type of the content, e.g. text/html. It has to be one of SUPPORTED_MIME_TYPES, unless WithInputConverter converts it to one.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed content.
+*/
+func (c *Client) ParseString(content string, mimeType string, mode LlamaParseMode, opts ...Option) (string, error) {
+	return c.with(opts).parseString(context.Background(), content, mimeType, mode, nil)
+}
+
+/*
+ParseDocuments parses a file in JSON mode and returns one LlamaIndex Document per page.
+
+Args:
+
+	file: The file to parse.
+	opts: Options for this call only.
+
+Returns:
+
+	The documents, each with the page text and the page number and job ID as metadata.
+	If only part of the file was parsed, the documents of the parsed pages come with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseDocuments(file []byte, opts ...Option) ([]Document, error) {
+	return c.with(opts).parseDocuments(context.Background(), file, nil)
+}
+
+/*
+ParseMultipartFile parses a file received in a multipart upload, e.g. from http.Request.FormFile. The file is streamed into the upload, and read again from the start if the upload is retried.
+
+Args:
+
+	file: The uploaded file.
+	header: Its header, the file name and Content-Type are passed on to LlamaParse.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed file.
+*/
+func (c *Client) ParseMultipartFile(file multipart.File, header *multipart.FileHeader, mode LlamaParseMode, opts ...Option) (string, error) {
+	return c.with(opts).parseMultipartFile(context.Background(), file, header, mode, nil)
+}
+
+/*
+ParseFS parses a file from a filesystem, such as an embed.FS.
+
+Args:
+
+	fsys: The filesystem to read from.
+	name: The path of the file in fsys. Its extension determines the MIME type.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed file.
+*/
+func (c *Client) ParseFS(fsys fs.FS, name string, mode LlamaParseMode, opts ...Option) (string, error) {
+	return c.with(opts).parseFS(context.Background(), fsys, name, mode, nil)
+}
+
+/*
+ParseMarkdownAndText parses a file once and returns both its markdown and its plain text result.
+
+Args:
+
+	file: The file to parse.
+	opts: Options for this call only.
+
+Returns:
+
+	The markdown and plain text of the parsed file. If only part of the file was parsed, they come with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseMarkdownAndText(file []byte, opts ...Option) (*MarkdownAndText, error) {
+	return c.with(opts).parseMarkdownAndText(context.Background(), file, nil)
+}
+
+/*
+WaitForCompletion waits for a parsing job to finish without fetching its result.
+
+Args:
+
+	jobID: The ID of the job.
+	submittedAt: When the job was submitted, the timeout counts from then. A zero time counts it from now.
+	opts: Options for this call only, e.g. WithTimeout or WithPollStrategy.
+
+Returns:
+
+	The final status of the job: SUCCESS, PARTIAL_SUCCESS, ERROR, FAILED or CANCELLED.
+*/
+func (c *Client) WaitForCompletion(jobID string, submittedAt time.Time, opts ...Option) (string, error) {
+	return c.with(opts).waitForCompletion(context.Background(), jobID, submittedAt)
+}
+
+/*
+GetResultRange fetches part of a finished job's result using an HTTP range request.
+
+Args:
+
+	jobID: The ID of the job.
+	mode: The output format (markdown, text, json).
+	start, end: The first and last byte of the raw result document to fetch, both inclusive.
+	opts: Options for this call only.
+
+Returns:
+
+	The requested bytes, or ErrRangeNotSupported if the server answered with the whole result.
+*/
+func (c *Client) GetResultRange(jobID string, mode LlamaParseMode, start int64, end int64, opts ...Option) ([]byte, error) {
+	return c.with(opts).getResultRange(context.Background(), jobID, mode, start, end)
+}
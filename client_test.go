@@ -0,0 +1,435 @@
+package llamaparse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer sits in front of a fakeServer and answers the requests fail returns a status code for with it, counting every request by method and path.
+type flakyServer struct {
+	*httptest.Server
+
+	mutex    sync.Mutex
+	requests map[string]int
+}
+
+func newFlakyServer(t *testing.T, server *fakeServer, fail func(r *http.Request, attempt int) (int, http.Header)) *flakyServer {
+	t.Helper()
+
+	flaky := &flakyServer{requests: map[string]int{}}
+	flaky.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		flaky.mutex.Lock()
+		flaky.requests[key]++
+		attempt := flaky.requests[key]
+		flaky.mutex.Unlock()
+
+		if statusCode, header := fail(r, attempt); statusCode != 0 {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			http.Error(w, http.StatusText(statusCode), statusCode)
+			return
+		}
+
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(flaky.Close)
+
+	return flaky
+}
+
+func (s *flakyServer) count(method string, path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[method+" "+path]
+}
+
+func (s *flakyServer) client(opts ...Option) *Client {
+	opts = append([]Option{
+		WithBaseURL(s.URL),
+		WithPollStrategy(FixedInterval{Interval: time.Millisecond}),
+	}, opts...)
+
+	return New("test-key", opts...)
+}
+
+func TestRetryAfter(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		switch {
+		case r.Method == "POST" && attempt <= 2:
+			return http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}
+		case r.URL.Path == "/api/parsing/job/job-1" && attempt == 1:
+			return http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}}
+		}
+		return 0, nil
+	})
+
+	result, err := flaky.client().Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "text" {
+		t.Errorf("got %q, want text", result)
+	}
+
+	if got := flaky.count("POST", "/api/parsing/upload"); got != 3 {
+		t.Errorf("got %d upload requests, want 3", got)
+	}
+	if uploads := server.uploadCount(); uploads != 1 {
+		t.Errorf("got %d uploads through, want 1", uploads)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		return http.StatusInternalServerError, http.Header{"Retry-After": {"0"}}
+	})
+
+	_, err := flaky.client(WithMaxRetries(2)).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("got %v, want an APIError with 500", err)
+	}
+
+	if got := flaky.count("POST", "/api/parsing/upload"); got != 3 {
+		t.Errorf("got %d upload requests, want 3", got)
+	}
+}
+
+func TestStatusErrorKeepsPolling(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		if r.Method == "GET" {
+			return http.StatusBadGateway, http.Header{"Retry-After": {"0"}}
+		}
+		return 0, nil
+	})
+
+	_, err := flaky.client(WithMaxRetries(0), WithTimeout(200*time.Millisecond)).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if !errors.Is(err, ErrTimeoutReached) {
+		t.Fatalf("got %v, want ErrTimeoutReached", err)
+	}
+
+	// a failing status check is only a missed check, the job is still polled until the timeout
+	if got := flaky.count("GET", "/api/parsing/job/job-1"); got < 3 {
+		t.Errorf("got %d status requests, want the job polled until the timeout", got)
+	}
+}
+
+func TestRetryAfterPastDeadline(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		return http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	_, err := flaky.client().ParseContext(ctx, []byte("text"), TEXT, WithFilename("a.txt"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got %v, want an APIError with 429", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("waited %v for a Retry-After past the deadline", elapsed)
+	}
+}
+
+func TestResultErrorNotRetriedTwice(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       int
+	}{
+		// not retried at all
+		{http.StatusUnauthorized, 1},
+		// only retried by do, not again for a missing result
+		{http.StatusInternalServerError, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.statusCode), func(t *testing.T) {
+			server := newFakeServer(t)
+			flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+				if strings.Contains(r.URL.Path, "/result/") {
+					return test.statusCode, http.Header{"Retry-After": {"0"}}
+				}
+				return 0, nil
+			})
+
+			_, err := flaky.client(WithMaxRetries(2)).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.statusCode {
+				t.Fatalf("got %v, want an APIError with %d", err, test.statusCode)
+			}
+
+			if got := flaky.count("GET", "/api/parsing/job/job-1/result/text"); got != test.want {
+				t.Errorf("got %d result requests, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResultNotReadyRetried(t *testing.T) {
+	for _, mode := range []LlamaParseMode{TEXT, JSON} {
+		t.Run(string(mode), func(t *testing.T) {
+			server := newFakeServer(t)
+			var mutex sync.Mutex
+			fetches := 0
+			server.result = func(job *fakeJob, mode string) (int, string) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				fetches++
+				if fetches == 1 {
+					// finished, but the result isn't there yet
+					return http.StatusOK, `{"job_metadata": {}}`
+				}
+				return 0, ""
+			}
+
+			result, err := server.client().Parse([]byte("text"), mode, WithFilename("a.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fetches != 2 {
+				t.Errorf("got %d fetches, want 2", fetches)
+			}
+
+			if mode == JSON {
+				parsed, err := DecodeResult([]byte(result))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(parsed.Pages) != 1 || parsed.Pages[0].Text != "text" {
+					t.Errorf("got pages %+v, want the page of the second fetch", parsed.Pages)
+				}
+			} else if result != "text" {
+				t.Errorf("got %q, want text", result)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	header := func(retryAfter string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {retryAfter}}}
+	}
+
+	if got := retryDelay(header("2"), 0); got != 2*time.Second {
+		t.Errorf("Retry-After 2: got %v", got)
+	}
+	if got := retryDelay(header(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)), 0); got != 0 {
+		t.Errorf("Retry-After in the past: got %v", got)
+	}
+	if got := retryDelay(header(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)), 0); got <= 58*time.Second || got > time.Minute {
+		t.Errorf("Retry-After in a minute: got %v", got)
+	}
+
+	for attempt := range 40 {
+		want := min(RETRY_INITIAL_DELAY<<min(attempt, 16), RETRY_MAX_DELAY)
+		got := retryDelay(&http.Response{Header: http.Header{}}, attempt)
+		if got < want/2 || got > want {
+			t.Errorf("attempt %d: got %v, want between %v and %v", attempt, got, want/2, want)
+		}
+	}
+}
+
+func TestStreamedUploadRetried(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		if r.Method == "POST" && attempt == 1 {
+			// read part of the upload before failing, so the retry has to start over
+			io.CopyN(io.Discard, r.Body, 1000)
+			return http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}}
+		}
+		return 0, nil
+	})
+
+	content := strings.Repeat("0123456789", 100000)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range [][]Option{nil, {WithMIMEAutoCorrect(true)}} {
+		result, err := flaky.client(opts...).ParseFile(path, TEXT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != content {
+			t.Errorf("got %d bytes, want the %d of the file", len(result), len(content))
+		}
+
+		flaky.mutex.Lock()
+		flaky.requests = map[string]int{}
+		flaky.mutex.Unlock()
+	}
+
+	// a reader that can't be rewound isn't retried
+	_, err = flaky.client().ParseReader(struct{ io.Reader }{strings.NewReader(content[:10])}, "a.txt", TEXT)
+	if err == nil {
+		t.Fatal("a reader that isn't a Seeker was retried")
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	server := newFakeServer(t)
+	server.status = func(job *fakeJob) string {
+		return "PENDING"
+	}
+
+	started := time.Now()
+	_, err := server.client(WithDeadline(time.Now().Add(100*time.Millisecond))).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if !errors.Is(err, ErrTimeoutReached) {
+		t.Errorf("got %v, want ErrTimeoutReached", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("the parse took %v past its deadline", elapsed)
+	}
+
+	// the earlier of the context's deadline and the option's is honored
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = server.client(WithDeadline(time.Now().Add(time.Hour))).ParseContext(ctx, []byte("text"), TEXT, WithFilename("a.txt"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the context's deadline", err)
+	}
+
+	_, err = server.client(WithDeadline(time.Now().Add(100*time.Millisecond))).WaitForCompletion("job-1", time.Time{})
+	if !errors.Is(err, ErrTimeoutReached) {
+		t.Errorf("WaitForCompletion: got %v, want ErrTimeoutReached", err)
+	}
+}
+
+func TestWithPaths(t *testing.T) {
+	tests := []struct {
+		paths Paths
+		valid bool
+	}{
+		{Paths{JobStatus: "/v2/jobs/%s"}, true},
+		{Paths{JobResult: "/v2/jobs/%s/%s", JobImage: "/v2/jobs/%s/images/%s"}, true},
+		{Paths{JobStatus: "/gateway/100%%/jobs/%s"}, true},
+		{Paths{Upload: "/v2/upload"}, true},
+		{Paths{JobStatus: "/v2/jobs"}, false},
+		{Paths{JobStatus: "/v2/jobs/%s/%s"}, false},
+		{Paths{JobResult: "/v2/jobs/%s"}, false},
+		{Paths{JobStatus: "/v2/jobs/%d"}, false},
+		{Paths{JobStatus: "/v2/jobs/%s%"}, false},
+		{Paths{JobImage: "/gateway/100%/jobs/%s/%s"}, false},
+	}
+
+	for _, test := range tests {
+		err := New("test-key", WithPaths(test.paths)).check()
+		if test.valid && err != nil {
+			t.Errorf("%+v: %v", test.paths, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidPaths) {
+			t.Errorf("%+v: got %v, want ErrInvalidPaths", test.paths, err)
+		}
+	}
+
+	// a literal % in a path that is kept through the formatting
+	server := newFakeServer(t)
+	remapped := New("test-key",
+		WithBaseURL(server.URL),
+		WithPollStrategy(FixedInterval{Interval: time.Millisecond}),
+		WithPaths(Paths{JobStatus: "/api/parsing/job/%s?gateway=100%%25"}),
+	)
+	_, err := remapped.Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithMultipartBoundary(t *testing.T) {
+	for boundary, valid := range map[string]bool{
+		"":                      true,
+		"fixed-boundary":        true,
+		"with space inside":     true,
+		"ends with a space ":    false,
+		"no\"quotes":            false,
+		strings.Repeat("b", 70): true,
+		strings.Repeat("b", 71): false,
+	} {
+		err := New("test-key", WithMultipartBoundary(boundary)).check()
+		if valid && err != nil {
+			t.Errorf("%q: %v", boundary, err)
+		}
+		if !valid && !errors.Is(err, ErrInvalidBoundary) {
+			t.Errorf("%q: got %v, want ErrInvalidBoundary", boundary, err)
+		}
+	}
+
+	var mutex sync.Mutex
+	var boundaries []string
+	flaky := newFlakyServer(t, newFakeServer(t), func(r *http.Request, attempt int) (int, http.Header) {
+		if r.Method == "POST" {
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			mutex.Lock()
+			boundaries = append(boundaries, params["boundary"])
+			mutex.Unlock()
+		}
+		return 0, nil
+	})
+	client := flaky.client(WithMultipartBoundary("fixed-boundary"), WithFilename("a.txt"))
+
+	// both the buffered and the streamed upload
+	_, err := client.Parse([]byte("text"), TEXT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ParseReader(strings.NewReader("text"), "a.txt", TEXT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !slices.Equal(boundaries, []string{"fixed-boundary", "fixed-boundary"}) {
+		t.Errorf("got boundaries %q, want fixed-boundary", boundaries)
+	}
+}
+
+func TestGetJobStatusError(t *testing.T) {
+	server := newFakeServer(t)
+	flaky := newFlakyServer(t, server, func(r *http.Request, attempt int) (int, http.Header) {
+		if r.URL.Path == "/api/parsing/job/job-1" {
+			return http.StatusServiceUnavailable, http.Header{"Retry-After": {"0"}}
+		}
+		return 0, nil
+	})
+
+	client := flaky.client(WithMaxRetries(1))
+	jobID, err := client.SubmitJob([]byte("text"), WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetJobStatus(jobID)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v, want an APIError with 503", err)
+	}
+
+	_, err = client.GetJobStatus("job-2")
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("got %v for a missing job, want ErrJobNotFound", err)
+	}
+}
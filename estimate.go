@@ -0,0 +1,83 @@
+package llamaparse
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+)
+
+// The standard parsing rate at the time of writing, premium parsing costs more per page.
+const DEFAULT_CREDITS_PER_PAGE = 1
+
+var (
+	ErrEstimateNotSupported = errors.New("the cost of this file cannot be estimated locally")
+
+	// The credits a page costs with each preset at the time of writing. Presets missing here cost DEFAULT_CREDITS_PER_PAGE.
+	PRESET_CREDITS_PER_PAGE = map[ParsePreset]float64{
+		FAST:     1,
+		BALANCED: DEFAULT_CREDITS_PER_PAGE,
+		PREMIUM:  15,
+	}
+
+	pdfPageObject = regexp.MustCompile(`/Type\s*/Page([^s]|$)`)
+)
+
+// Estimate is an approximation of what parsing a file will cost.
+type Estimate struct {
+	Pages   int
+	Credits float64
+}
+
+/*
+EstimateCost approximates the cost of parsing a file without uploading it.
+
+Only PDFs are supported. Pages are counted from the page objects in the file, so PDFs that keep them in compressed object streams can't be estimated.
+
+Args:
+
+	file: The file to estimate.
+	opts: The options the file would be parsed with. Only WithPreset changes the estimate.
+
+Returns:
+
+	The number of pages and the credits they cost at the rate of the preset in PRESET_CREDITS_PER_PAGE, DEFAULT_CREDITS_PER_PAGE without one.
+*/
+func EstimateCost(file []byte, opts ...Option) (Estimate, error) {
+	c := (&Client{}).with(opts)
+	if c.err != nil {
+		return Estimate{}, c.err
+	}
+
+	pages, err := countPages(file)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	creditsPerPage, ok := PRESET_CREDITS_PER_PAGE[c.preset]
+	if !ok {
+		creditsPerPage = DEFAULT_CREDITS_PER_PAGE
+	}
+
+	return Estimate{
+		Pages:   pages,
+		Credits: float64(pages) * creditsPerPage,
+	}, nil
+}
+
+// countPages counts the pages of a PDF from its page objects, failing with ErrEstimateNotSupported when it can't.
+func countPages(file []byte) (int, error) {
+	if len(file) == 0 {
+		return 0, ErrEmptyFile
+	}
+
+	if !bytes.HasPrefix(file, []byte("%PDF-")) {
+		return 0, ErrEstimateNotSupported
+	}
+
+	pages := len(pdfPageObject.FindAllIndex(file, -1))
+	if pages == 0 {
+		return 0, ErrEstimateNotSupported
+	}
+
+	return pages, nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/X3NOOO/llamaparse-go"
 )
@@ -10,9 +9,9 @@ import (
 const FILENAME = "somatosensory.pdf"
 
 func main() {
-	file, _ := os.ReadFile(FILENAME)
+	client := llamaparse.New("")
 
-	parsedText, err := llamaparse.Parse(file, llamaparse.MARKDOWN, nil, nil, nil, nil)
+	parsedText, err := client.ParseFile(FILENAME, llamaparse.MARKDOWN)
 	if err != nil {
 		panic(err)
 	}
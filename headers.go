@@ -0,0 +1,175 @@
+package llamaparse
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// How many lines from the top and bottom of a page are considered headers and footers.
+	REPEATED_HEADER_LINES = 3
+	// A header has to appear on at least this many pages to be removed.
+	REPEATED_HEADER_MIN_PAGES = 3
+)
+
+var number = regexp.MustCompile(`\d+`)
+
+type linePosition struct {
+	// negative from the bottom of the page
+	index int
+	line  string
+}
+
+// normalizeHeaderLine makes lines that only differ by the page number compare equal.
+func normalizeHeaderLine(line string, pageNumber int) string {
+	page := strconv.Itoa(pageNumber)
+	replaced := false
+
+	// only the first occurrence, so "Page 4 of 4" still matches "Page 3 of 4"
+	return number.ReplaceAllStringFunc(strings.TrimSpace(line), func(n string) string {
+		if n == page && !replaced {
+			replaced = true
+			return "#"
+		}
+		return n
+	})
+}
+
+// edgeLines returns the indexes of the first and last non-empty lines of a page with their position.
+// Table rows take up a position but are never returned, a table continued over several pages repeats its header and separator rows on each one.
+func edgeLines(lines []string, pageNumber int) map[int]linePosition {
+	edges := map[int]linePosition{}
+
+	seen := 0
+	for i := 0; i < len(lines) && seen < REPEATED_HEADER_LINES; i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			if !isTableRow(lines[i]) {
+				edges[i] = linePosition{index: seen, line: normalizeHeaderLine(lines[i], pageNumber)}
+			}
+			seen++
+		}
+	}
+
+	seen = 0
+	for i := len(lines) - 1; i >= 0 && seen < REPEATED_HEADER_LINES; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			if _, ok := edges[i]; !ok && !isTableRow(lines[i]) {
+				edges[i] = linePosition{index: -seen - 1, line: normalizeHeaderLine(lines[i], pageNumber)}
+			}
+			seen++
+		}
+	}
+
+	return edges
+}
+
+func stripRepeated(contents []string, pageNumbers []int) []string {
+	pages := make([][]string, len(contents))
+	edges := make([]map[int]linePosition, len(contents))
+	counts := map[linePosition]int{}
+
+	for i, content := range contents {
+		pages[i] = strings.Split(content, "\n")
+		edges[i] = edgeLines(pages[i], pageNumbers[i])
+
+		// a line counts once per page
+		seen := map[linePosition]bool{}
+		for _, position := range edges[i] {
+			if !seen[position] {
+				counts[position]++
+				seen[position] = true
+			}
+		}
+	}
+
+	threshold := max(REPEATED_HEADER_MIN_PAGES, len(contents)/2+1)
+
+	stripped := make([]string, len(contents))
+	for i, lines := range pages {
+		kept := make([]string, 0, len(lines))
+		for j, line := range lines {
+			position, ok := edges[i][j]
+			if ok && counts[position] >= threshold {
+				continue
+			}
+			kept = append(kept, line)
+		}
+		stripped[i] = strings.TrimSpace(strings.Join(kept, "\n"))
+	}
+
+	return stripped
+}
+
+/*
+StripRepeatedHeaders removes headers and footers repeated across the pages of a JSON mode result.
+
+A line is removed when it is among the first or last REPEATED_HEADER_LINES lines of a page, isn't a table row and appears at the same position on most pages, and on at least REPEATED_HEADER_MIN_PAGES of them.
+The page's own number is ignored when comparing lines, so "Page 3 of 10" on page 3 matches "Page 4 of 10" on page 4.
+
+Args:
+
+	pages: The pages of the result, from DecodeResult.
+
+Returns:
+
+	Copies of the pages with the repeated lines removed from Markdown and Text.
+*/
+func StripRepeatedHeaders(pages []Page) []Page {
+	markdown := make([]string, len(pages))
+	text := make([]string, len(pages))
+	pageNumbers := make([]int, len(pages))
+	for i, page := range pages {
+		markdown[i] = page.Markdown
+		text[i] = page.Text
+		pageNumbers[i] = page.Page
+	}
+
+	markdown = stripRepeated(markdown, pageNumbers)
+	text = stripRepeated(text, pageNumbers)
+
+	stripped := make([]Page, len(pages))
+	for i, page := range pages {
+		page.Markdown = markdown[i]
+		page.Text = text[i]
+		stripped[i] = page
+	}
+
+	return stripped
+}
+
+// WithStripRepeatedHeaders makes markdown and text results come without the headers and footers repeated across their pages, see StripRepeatedHeaders.
+// The pages are told apart by fetching the JSON result of the same job, which costs a request but no parsing.
+func WithStripRepeatedHeaders(strip bool) Option {
+	return func(c *Client) {
+		c.stripRepeatedHeaders = strip
+	}
+}
+
+// stripJobHeaders rebuilds the markdown or text result of a finished job from its JSON pages with StripRepeatedHeaders applied.
+func (c *Client) stripJobHeaders(ctx context.Context, jobID string, mode LlamaParseMode) (string, error) {
+	resultBytes, err := c.getResultBytes(ctx, jobID, JSON)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := DecodeResult(resultBytes)
+	if err != nil {
+		return "", err
+	}
+
+	pages := StripRepeatedHeaders(result.Pages)
+	contents := make([]string, len(pages))
+	for i, page := range pages {
+		contents[i] = page.Text
+		if mode == MARKDOWN {
+			contents[i] = page.Markdown
+		}
+	}
+
+	if mode == MARKDOWN {
+		return strings.Join(contents, "\n\n"+DEFAULT_PAGE_SEPARATOR+"\n\n"), nil
+	}
+	return strings.Join(contents, "\n\n"), nil
+}
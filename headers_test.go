@@ -0,0 +1,117 @@
+package llamaparse
+
+import (
+	"strings"
+	"testing"
+)
+
+// pagesOf numbers contents as pages from 1. Lines only differing by the page number match like a numbered footer, so bodies avoid it.
+func pagesOf(contents ...string) []Page {
+	pages := make([]Page, len(contents))
+	for i, content := range contents {
+		pages[i] = Page{Page: i + 1, Markdown: content, Text: content}
+	}
+
+	return pages
+}
+
+func TestStripRepeatedHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []Page
+		want  []string
+	}{
+		{
+			name:  "header and footer",
+			pages: pagesOf("ACME Corp\nfirst body\nConfidential", "ACME Corp\nsecond body\nConfidential", "ACME Corp\nthird body\nConfidential"),
+			want:  []string{"first body", "second body", "third body"},
+		},
+		{
+			name:  "page numbers",
+			pages: pagesOf("first body\nPage 1 of 3", "second body\nPage 2 of 3", "third body\nPage 3 of 3"),
+			want:  []string{"first body", "second body", "third body"},
+		},
+		{
+			name:  "too few pages",
+			pages: pagesOf("ACME Corp\nfirst body", "ACME Corp\nsecond body"),
+			want:  []string{"ACME Corp\nfirst body", "ACME Corp\nsecond body"},
+		},
+		{
+			name:  "not on most pages",
+			pages: pagesOf("ACME Corp\nfirst body", "ACME Corp\nsecond body", "ACME Corp\nthird body", "fourth body", "fifth body", "sixth body", "seventh body"),
+			want:  []string{"ACME Corp\nfirst body", "ACME Corp\nsecond body", "ACME Corp\nthird body", "fourth body", "fifth body", "sixth body", "seventh body"},
+		},
+		{
+			name:  "different position",
+			pages: pagesOf("ACME Corp\nfirst body", "intro\nACME Corp\nsecond body", "more\nintro\nACME Corp\nthird body"),
+			want:  []string{"ACME Corp\nfirst body", "intro\nACME Corp\nsecond body", "more\nintro\nACME Corp\nthird body"},
+		},
+		{
+			name: "continued table",
+			pages: pagesOf(
+				"| Name | Amount |\n|---|---|\n| a | 1 |",
+				"| Name | Amount |\n|---|---|\n| b | 2 |",
+				"| Name | Amount |\n|---|---|\n| c | 3 |",
+				"| Name | Amount |\n|---|---|\n| d | 4 |",
+			),
+			want: []string{
+				"| Name | Amount |\n|---|---|\n| a | 1 |",
+				"| Name | Amount |\n|---|---|\n| b | 2 |",
+				"| Name | Amount |\n|---|---|\n| c | 3 |",
+				"| Name | Amount |\n|---|---|\n| d | 4 |",
+			},
+		},
+		{
+			name: "header above a continued table",
+			pages: pagesOf(
+				"ACME Corp\n| Name | Amount |\n|---|---|\n| a | 1 |",
+				"ACME Corp\n| Name | Amount |\n|---|---|\n| b | 2 |",
+				"ACME Corp\n| Name | Amount |\n|---|---|\n| c | 3 |",
+			),
+			want: []string{
+				"| Name | Amount |\n|---|---|\n| a | 1 |",
+				"| Name | Amount |\n|---|---|\n| b | 2 |",
+				"| Name | Amount |\n|---|---|\n| c | 3 |",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := StripRepeatedHeaders(test.pages)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d pages, want %d", len(got), len(test.want))
+			}
+
+			for i, page := range got {
+				if page.Markdown != test.want[i] || page.Text != test.want[i] {
+					t.Errorf("page %d: got markdown %q and text %q, want %q", i+1, page.Markdown, page.Text, test.want[i])
+				}
+				if page.Page != test.pages[i].Page {
+					t.Errorf("page %d: got number %d", i+1, page.Page)
+				}
+			}
+		})
+	}
+}
+
+func TestWithStripRepeatedHeaders(t *testing.T) {
+	server := newFakeServer(t)
+	file := strings.Join([]string{"ACME Corp\nfirst body", "ACME Corp\nsecond body", "ACME Corp\nthird body"}, "\f")
+
+	markdown, err := server.client(WithStripRepeatedHeaders(true)).Parse([]byte(file), MARKDOWN, WithFilename("report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "first body\n\n---\n\nsecond body\n\n---\n\nthird body"; markdown != want {
+		t.Errorf("got markdown %q, want %q", markdown, want)
+	}
+
+	text, err := server.client(WithStripRepeatedHeaders(true)).Parse([]byte(file), TEXT, WithFilename("report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "first body\n\nsecond body\n\nthird body"; text != want {
+		t.Errorf("got text %q, want %q", text, want)
+	}
+}
@@ -0,0 +1,239 @@
+package llamaparse
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// The Type of the page renderings WithTakeScreenshot adds to the images of a page.
+const SCREENSHOT_IMAGE_TYPE = "full_page_screenshot"
+
+// NamedImage is an image LlamaParse extracted from a file.
+type NamedImage struct {
+	Name string
+	// Page is the page the image was found on.
+	Page int
+	Data []byte
+}
+
+/*
+DownloadImage downloads an image extracted by a finished job.
+
+Args:
+
+	jobID: The ID of the job.
+	name: The name of the image, as listed in the Images of a page in the JSON result.
+
+Returns:
+
+	The image file.
+*/
+func (c *Client) DownloadImage(jobID string, name string) ([]byte, error) {
+	err := c.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.downloadImage(context.Background(), jobID, name)
+}
+
+/*
+GetImages downloads every image extracted by a finished job.
+
+The image names are read from the job's JSON result, which is fetched first.
+
+Args:
+
+	jobID: The ID of the job.
+
+Returns:
+
+	The images in the order of the pages they are on.
+*/
+func (c *Client) GetImages(jobID string) ([]NamedImage, error) {
+	err := c.check()
+	if err != nil {
+		return nil, err
+	}
+
+	resultBytes, err := c.getResultBytes(context.Background(), jobID, JSON)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := DecodeResult(resultBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []NamedImage
+	for _, page := range result.Pages {
+		for _, image := range page.Images {
+			data, err := c.downloadImage(context.Background(), jobID, image.Name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", image.Name, err)
+			}
+
+			images = append(images, NamedImage{
+				Name: image.Name,
+				Page: page.Page,
+				Data: data,
+			})
+		}
+	}
+
+	return images, nil
+}
+
+func (c *Client) downloadImage(ctx context.Context, jobID string, name string) ([]byte, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf(c.paths.JobImage, url.PathEscape(jobID), url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	return c.readResultBody(resp.Body)
+}
+
+// WithTakeScreenshot makes LlamaParse render every page as an image, listed among the page's images with the SCREENSHOT_IMAGE_TYPE.
+func WithTakeScreenshot(take bool) Option {
+	return func(c *Client) {
+		c.takeScreenshot = take
+	}
+}
+
+// PageWithImage is the text of a page together with its rendering.
+type PageWithImage struct {
+	Page int
+	Text string
+	// Image is the rendered page, nil if LlamaParse didn't render it.
+	Image []byte
+}
+
+/*
+ParseRangeWithImages parses some pages of a file and returns the text of each together with an image of the rendered page.
+
+Args:
+
+	file: The file to parse.
+	pages: The pages to parse, in the format of WithTargetPages.
+	opts: Options for this call only.
+
+Returns:
+
+	The parsed pages in order. If only some of them were parsed, they come with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseRangeWithImages(file []byte, pages string, opts ...Option) ([]PageWithImage, error) {
+	c = c.with(opts).with([]Option{WithTargetPages(pages), WithTakeScreenshot(true)})
+
+	jobID, resultBytes, err := c.parseFile(context.Background(), file, c.fileName, "", JSON, nil)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	result, decodeErr := DecodeResult(resultBytes)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	parsed := make([]PageWithImage, 0, len(result.Pages))
+	for _, page := range result.Pages {
+		pageWithImage := PageWithImage{
+			Page: page.Page,
+			Text: page.Text,
+		}
+
+		for _, image := range page.Images {
+			if image.Type != SCREENSHOT_IMAGE_TYPE {
+				continue
+			}
+
+			data, imageErr := c.downloadImage(context.Background(), jobID, image.Name)
+			if imageErr != nil {
+				return nil, fmt.Errorf("%s: %w", image.Name, imageErr)
+			}
+			pageWithImage.Image = data
+			break
+		}
+
+		parsed = append(parsed, pageWithImage)
+	}
+
+	return parsed, err
+}
+
+// A markdown image, ![alt](link) with an optional "title" after the link.
+var markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)((?:\s+"[^"]*")?)\)`)
+
+// WithInlineImages makes markdown results self-contained: the images they link to are downloaded and embedded as base64 data URIs.
+// Only links to images extracted by the job are inlined, links with a scheme, like https or data, and to images the job doesn't have are left as they are.
+func WithInlineImages(inline bool) Option {
+	return func(c *Client) {
+		c.inlineImages = inline
+	}
+}
+
+// inlineJobImages replaces the links in markdown to images of the job with data URIs of the images. Each image is downloaded once.
+func (c *Client) inlineJobImages(ctx context.Context, jobID string, markdown string) (string, error) {
+	dataURIs := map[string]string{}
+
+	var err error
+	inlined := markdownImage.ReplaceAllStringFunc(markdown, func(image string) string {
+		if err != nil {
+			return image
+		}
+
+		match := markdownImage.FindStringSubmatch(image)
+		name := match[2]
+		if strings.Contains(name, ":") {
+			return image
+		}
+
+		dataURI, ok := dataURIs[name]
+		if !ok {
+			var data []byte
+			data, err = c.downloadImage(ctx, jobID, name)
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				// not one of the job's images, e.g. a link copied from the document
+				err = nil
+				return image
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", name, err)
+				return image
+			}
+
+			mimeType := mime.TypeByExtension(path.Ext(name))
+			if mimeType == "" {
+				mimeType = http.DetectContentType(data)
+			}
+			dataURI = "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+			dataURIs[name] = dataURI
+		}
+
+		return "![" + match[1] + "](" + dataURI + match[3] + ")"
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return inlined, nil
+}
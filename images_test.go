@@ -0,0 +1,40 @@
+package llamaparse
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestWithInlineImages(t *testing.T) {
+	server := newFakeServer(t)
+	server.images = map[string]string{
+		"img_p0_1.png": "\x89PNG\r\n\x1a\nfake",
+		"chart.jpg":    "\xff\xd8\xfffake",
+	}
+	markdown := "# Report\n\n![logo](img_p0_1.png)\n\n![chart](chart.jpg \"Q1 revenue\")\n\n![again](img_p0_1.png)\n\n" +
+		"![remote](https://example.com/a.png) ![missing](scan.png)"
+	server.result = func(job *fakeJob, mode string) (int, string) {
+		body, _ := json.Marshal(map[string]string{mode: markdown})
+		return http.StatusOK, string(body)
+	}
+
+	got, err := server.client(WithInlineImages(true)).Parse([]byte("file"), MARKDOWN, WithFilename("a.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# Report\n\n![logo](data:image/png;base64,iVBORw0KGgpmYWtl)\n\n![chart](data:image/jpeg;base64,/9j/ZmFrZQ== \"Q1 revenue\")\n\n![again](data:image/png;base64,iVBORw0KGgpmYWtl)\n\n" +
+		"![remote](https://example.com/a.png) ![missing](scan.png)"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, err = server.client().Parse([]byte("file"), MARKDOWN, WithFilename("a.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != markdown {
+		t.Errorf("got %q without WithInlineImages, want it unchanged", got)
+	}
+}
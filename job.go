@@ -0,0 +1,90 @@
+package llamaparse
+
+import (
+	"bytes"
+	"context"
+)
+
+/*
+SubmitJob uploads a file for parsing and returns without waiting for the job to finish.
+
+The job ID can be stored and handed to GetJobStatus and GetResult later, even from another process.
+With WithWebhookURL, LlamaParse calls the webhook once the job finishes, so the job doesn't have to be polled at all.
+The output format is picked when fetching the result, so the upload doesn't need one.
+
+Args:
+
+	file: The file to parse.
+	opts: Options for this call only, e.g. WithFilename.
+
+Returns:
+
+	The ID of the job.
+*/
+func (c *Client) SubmitJob(file []byte, opts ...Option) (string, error) {
+	c = c.with(opts)
+
+	if len(file) == 0 && !c.allowEmptyFile {
+		return "", ErrEmptyFile
+	}
+
+	err := c.check()
+	if err != nil {
+		return "", err
+	}
+
+	return c.submitJob(context.Background(), bytes.NewReader(file), c.fileName, detectMimeType(file, c.fileName), nil, false)
+}
+
+/*
+GetJobStatus checks the status of a job once.
+
+Args:
+
+	jobID: The ID of the job.
+
+Returns:
+
+	The status as LlamaParse reports it, e.g. PENDING, SUCCESS, PARTIAL_SUCCESS, ERROR or CANCELLED.
+	A request that failed after its retries returns the *APIError of the last response.
+*/
+func (c *Client) GetJobStatus(jobID string) (string, error) {
+	err := c.check()
+	if err != nil {
+		return "", err
+	}
+
+	status, err := c.getJobStatus(context.Background(), jobID)
+	if err != nil {
+		return "", err
+	}
+
+	// a response without a status
+	if status.Status == "" {
+		return "", ErrParsingFailed
+	}
+
+	return status.Status, nil
+}
+
+/*
+GetResult fetches the result of a finished job. It doesn't wait for the job, use GetJobStatus or WaitForCompletion first.
+
+Args:
+
+	jobID: The ID of the job.
+	mode: The output format (markdown, text, json).
+
+Returns:
+
+	The parsed file, the same as Parse returns it.
+*/
+func (c *Client) GetResult(jobID string, mode LlamaParseMode) (string, error) {
+	err := c.check()
+	if err != nil {
+		return "", err
+	}
+
+	resultBytes, err := c.getResultBytes(context.Background(), jobID, mode)
+	return c.decodeJobResult(context.Background(), jobID, resultBytes, mode, err)
+}
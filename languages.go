@@ -0,0 +1,24 @@
+package llamaparse
+
+import (
+	"fmt"
+	"slices"
+)
+
+// sos: the Language enum of llama_parse/utils.py, the codes of the OCR engine LlamaParse runs.
+var SUPPORTED_LANGUAGES = []string{"af", "az", "bs", "cs", "cy", "da", "de", "en", "es", "et", "fr", "ga", "hr", "hu", "id", "is", "it", "ku", "la", "lt", "lv", "mi", "ms", "mt", "nl", "no", "oc", "pi", "pl", "pt", "ro", "rs_latin", "sk", "sl", "sq", "sv", "sw", "tl", "tr", "uz", "vi", "ar", "fa", "ug", "ur", "bn", "as", "mni", "ru", "rs_cyrillic", "be", "bg", "uk", "mn", "abq", "ady", "kbd", "ava", "dar", "inh", "che", "lbe", "lez", "tab", "tjk", "hi", "mr", "ne", "bh", "mai", "ang", "bho", "mah", "sck", "new", "gom", "sa", "bgc", "th", "ch_sim", "ch_tra", "ja", "ko", "ta", "te", "kn"}
+
+// WithLanguages sets the languages of the file, e.g. []string{"en", "ar"} for a bilingual document.
+// Each has to be one of SUPPORTED_LANGUAGES, otherwise the parse fails with ErrUnsupportedLanguage before anything is uploaded.
+func WithLanguages(languages []string) Option {
+	return func(c *Client) {
+		for _, language := range languages {
+			if !slices.Contains(SUPPORTED_LANGUAGES, language) {
+				c.err = fmt.Errorf("%w: %q", ErrUnsupportedLanguage, language)
+				return
+			}
+		}
+
+		c.languages = slices.Clone(languages)
+	}
+}
@@ -0,0 +1,107 @@
+package llamaparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// largePage is a page of a ParseLarge chunk, with how far it was from the nearest edge of its chunk.
+type largePage struct {
+	page   Page
+	margin int
+}
+
+/*
+ParseLarge parses a PDF too large for a single job in chunks of chunkPages pages and joins their markdown back together.
+
+Consecutive chunks share overlapPages pages, so a paragraph or table crossing a chunk boundary is seen whole by one of them.
+A page parsed twice is taken from the chunk it is further inside of, and tables split by a page break are merged with MergeTables.
+
+Args:
+
+	file: The PDF to parse. Its pages are counted locally, so it has to be one EstimateCost supports, otherwise ErrEstimateNotSupported is returned.
+	chunkPages: How many pages each job parses.
+	overlapPages: How many pages consecutive chunks share. It has to be smaller than chunkPages.
+	opts: Options for this call only. WithTargetPages is replaced by the pages of each chunk.
+
+Returns:
+
+	The markdown of the whole file, its pages separated by DEFAULT_PAGE_SEPARATOR.
+	If only part of a chunk was parsed, the rest comes with its *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseLarge(file []byte, chunkPages int, overlapPages int, opts ...Option) (string, error) {
+	c = c.with(opts)
+
+	if chunkPages < 1 || overlapPages < 0 || overlapPages >= chunkPages {
+		return "", fmt.Errorf("%w: chunks of %d pages overlapping by %d", ErrInvalidTargetPages, chunkPages, overlapPages)
+	}
+
+	pageCount, err := countPages(file)
+	if err != nil {
+		return "", err
+	}
+
+	pages := map[int]largePage{}
+	var partialErrs []error
+	for start := 0; start < pageCount; start += chunkPages - overlapPages {
+		end := min(start+chunkPages, pageCount) - 1
+
+		chunk := c.with([]Option{WithTargetPages(fmt.Sprintf("%d-%d", start, end))})
+		_, resultBytes, err := chunk.parseFile(context.Background(), file, c.fileName, "", JSON, nil)
+		if err != nil && !errors.Is(err, ErrPartialSuccess) {
+			return "", fmt.Errorf("pages %d-%d: %w", start, end, err)
+		}
+		if err != nil {
+			partialErrs = append(partialErrs, fmt.Errorf("pages %d-%d: %w", start, end, err))
+		}
+
+		result, err := DecodeResult(resultBytes)
+		if err != nil {
+			return "", err
+		}
+
+		// page numbers count from 1, a result numbering its pages from the start of the chunk is placed by position
+		numbered := true
+		for _, page := range result.Pages {
+			if page.Page-1 < start || page.Page-1 > end {
+				numbered = false
+				break
+			}
+		}
+
+		for i, page := range result.Pages {
+			number := start + i
+			if numbered {
+				number = page.Page - 1
+			}
+
+			margin := min(number-start, end-number)
+			previous, ok := pages[number]
+			if !ok || margin > previous.margin {
+				pages[number] = largePage{page: page, margin: margin}
+			}
+		}
+
+		if end == pageCount-1 {
+			break
+		}
+	}
+
+	numbers := make([]int, 0, len(pages))
+	for number := range pages {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+
+	contents := make([]string, len(numbers))
+	for i, number := range numbers {
+		contents[i] = pages[number].page.Markdown
+	}
+
+	markdown := strings.Join(contents, "\n\n"+DEFAULT_PAGE_SEPARATOR+"\n\n")
+
+	return MergeTables(markdown, DEFAULT_PAGE_SEPARATOR), errors.Join(partialErrs...)
+}
@@ -0,0 +1,70 @@
+package llamaparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// largePageMarkdown is the markdown of page (counted from 0) as parsed by the chunk starting at start. Pages 3 and 4 share a table.
+func largePageMarkdown(page int, start int) string {
+	switch page {
+	case 3:
+		return fmt.Sprintf("page 3 of chunk %d\n\n| n | chunk |\n|---|---|\n| 3 | %d |", start, start)
+	case 4:
+		return fmt.Sprintf("| n | chunk |\n|---|---|\n| 4 | %d |\n\npage 4 of chunk %d", start, start)
+	}
+	return fmt.Sprintf("page %d of chunk %d", page, start)
+}
+
+func TestParseLarge(t *testing.T) {
+	file := []byte("%PDF-1.7\n" + strings.Repeat("<< /Type /Page >>\n", 7))
+
+	for _, renumbered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("renumbered %v", renumbered), func(t *testing.T) {
+			server := newFakeServer(t)
+			server.result = func(job *fakeJob, mode string) (int, string) {
+				var start, end int
+				fmt.Sscanf(job.form["target_pages"][0], "%d-%d", &start, &end)
+
+				var pages []Page
+				for page := start; page <= end; page++ {
+					number := page + 1
+					if renumbered {
+						// numbered from the first target page
+						number = page - start + 1
+					}
+					pages = append(pages, Page{Page: number, Markdown: largePageMarkdown(page, start)})
+				}
+
+				body, _ := json.Marshal(ParseResult{Pages: pages})
+				return http.StatusOK, string(body)
+			}
+
+			markdown, err := server.client().ParseLarge(file, 4, 2, WithFilename("large.pdf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// chunks 0-3, 2-5 and 4-6, each shared page taken from the chunk it is further inside of
+			want := strings.Join([]string{
+				"page 0 of chunk 0",
+				"page 1 of chunk 0",
+				"page 2 of chunk 0",
+				// the table continued on the next page is merged across the separator
+				"page 3 of chunk 2\n\n| n | chunk |\n|---|---|\n| 3 | 2 |\n| 4 | 2 |\n\npage 4 of chunk 2",
+				"page 5 of chunk 4",
+				"page 6 of chunk 4",
+			}, "\n\n---\n\n")
+			if markdown != want {
+				t.Errorf("got\n%s\nwant\n%s", markdown, want)
+			}
+
+			if uploads := server.uploadCount(); uploads != 3 {
+				t.Errorf("got %d chunks, want 3", uploads)
+			}
+		})
+	}
+}
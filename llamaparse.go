@@ -2,17 +2,143 @@ package llamaparse
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"mime"
 	"mime/multipart"
 	"net/http"
-	"os"
+	"net/textproto"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 )
 
 type LlamaParseMode string
 
+// Result is a parsed file along with what LlamaParse reported about the job.
+// The page count and the credits used are in Metadata.
+type Result struct {
+	Content string
+	JobID   string
+	// Status is the final status of the job, SUCCESS or PARTIAL_SUCCESS.
+	Status string
+	// Cached is true when the result came from the LlamaParse cache and was not billed.
+	Cached   bool
+	Metadata JobMetadata
+	// Fallback is true when the first job failed and the result is the one parsed with the options of WithFallback.
+	Fallback bool
+}
+
+// MarkdownAndText is the markdown and plain text result of the same job.
+type MarkdownAndText struct {
+	Markdown string
+	Text     string
+}
+
+// Document mirrors the JSON shape of a LlamaIndex Document.
+type Document struct {
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+type uploadResponse struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+	// detail is a string for most errors, but validation errors send a list of objects
+	Detail json.RawMessage `json:"detail"`
+}
+
+// message returns the error LlamaParse sent instead of a job, if any.
+func (r uploadResponse) message() string {
+	if r.Error != "" {
+		return r.Error
+	}
+
+	var detail string
+	if json.Unmarshal(r.Detail, &detail) == nil {
+		return detail
+	}
+
+	return string(r.Detail)
+}
+
+type statusResponse struct {
+	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// message returns why the job failed, if LlamaParse said so.
+func (r statusResponse) message() string {
+	if r.ErrorCode != "" && r.ErrorMessage != "" {
+		return r.ErrorCode + ": " + r.ErrorMessage
+	}
+
+	return r.ErrorCode + r.ErrorMessage
+}
+
+// JobError is returned when a job finished without fully succeeding.
+// It wraps ErrPartialSuccess for PARTIAL_SUCCESS, and ErrParsingFailed for every other status.
+type JobError struct {
+	JobID  string
+	Status string
+	// Message is the error LlamaParse reported for the job, if any.
+	Message string
+
+	err error
+}
+
+func (e *JobError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: job %s finished with status %s: %s", e.err, e.JobID, e.Status, e.Message)
+	}
+
+	return fmt.Sprintf("%s: job %s finished with status %s", e.err, e.JobID, e.Status)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.err
+}
+
+// APIError is returned when the API answers with an unexpected HTTP status. It wraps ErrParsingFailed.
+type APIError struct {
+	StatusCode int
+	// Body is the response body, cut off after ERROR_BODY_SIZE_BYTES.
+	Body string
+	URL  string
+	// RequestID is the REQUEST_ID_HEADER the request was sent with, see WithRequestID.
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s: %s answered with %d (request %s): %s", ErrParsingFailed, e.URL, e.StatusCode, e.RequestID, e.Body)
+	}
+	return fmt.Sprintf("%s: %s answered with %d: %s", ErrParsingFailed, e.URL, e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	return ErrParsingFailed
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, ERROR_BODY_SIZE_BYTES))
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+		URL:        resp.Request.URL.String(),
+		RequestID:  resp.Request.Header.Get(REQUEST_ID_HEADER),
+	}
+}
+
 const (
 	MARKDOWN LlamaParseMode = "markdown"
 	TEXT     LlamaParseMode = "text"
@@ -21,124 +147,666 @@ const (
 	BASE_URL                       = "https://api.cloud.llamaindex.ai"
 	DEFAULT_MAX_TIMEOUT_SECONDS    = 2000
 	DEFAULT_CHECK_INTERVAL_SECONDS = 1
+	// The check interval grows by DEFAULT_CHECK_FACTOR after each check, so long jobs aren't checked every second.
+	DEFAULT_MAX_CHECK_INTERVAL_SECONDS = 10
+	DEFAULT_CHECK_FACTOR               = 2
+	// A single hung connection shouldn't hold a call for the whole job timeout.
+	DEFAULT_REQUEST_TIMEOUT_SECONDS = 120
+	DEFAULT_MAX_RESULT_SIZE_BYTES   = 256 * 1024 * 1024
+	// An archive of a few kilobytes can extract to gigabytes, no file LlamaParse accepts is that large.
+	DEFAULT_MAX_ZIP_ENTRY_SIZE_BYTES = 512 * 1024 * 1024
+	// Enough to hold any error message, while a misbehaving proxy can't make an error hold megabytes of HTML.
+	ERROR_BODY_SIZE_BYTES = 64 * 1024
+	// The size of the buffer files are copied into the upload with.
+	DEFAULT_UPLOAD_BUFFER_SIZE_BYTES = 32 * 1024
+
+	RESULT_FETCH_RETRIES     = 3
+	RESULT_FETCH_RETRY_DELAY = 500 * time.Millisecond
+	// How many formats of one job ParseMulti fetches at the same time.
+	RESULT_FETCH_CONCURRENCY = 4
+
+	// Requests answered with 429 or a 5xx are retried, waiting as long as Retry-After says or backing off exponentially from RETRY_INITIAL_DELAY.
+	DEFAULT_MAX_RETRIES = 5
+	RETRY_INITIAL_DELAY = 500 * time.Millisecond
+	RETRY_MAX_DELAY     = 30 * time.Second
+
+	// The header WithRequestID sends the correlation ID in.
+	REQUEST_ID_HEADER = "X-Request-ID"
+
+	// Default paths of the API endpoints, relative to BASE_URL. WithPaths replaces them when going through a gateway that remaps them.
+	UPLOAD_PATH     = "/api/parsing/upload"
+	JOB_STATUS_PATH = "/api/parsing/job/%s"                 // job ID
+	JOB_RESULT_PATH = "/api/parsing/job/%s/result/%s"       // job ID, mode
+	JOB_IMAGE_PATH  = "/api/parsing/job/%s/result/image/%s" // job ID, image name
 )
 
 var (
-	ErrNoAPIKey       = errors.New("LlamaCloud API key is required")
-	ErrEmptyFile      = errors.New("the file cannot be empty")
-	ErrParsingFailed  = errors.New("parsing the file failed")
-	ErrTimeoutReached = errors.New("timeout reached while parsing the file")
+	ErrNoAPIKey         = errors.New("LlamaCloud API key is required")
+	ErrEmptyFile        = errors.New("the file cannot be empty")
+	ErrParsingFailed    = errors.New("parsing the file failed")
+	ErrTimeoutReached   = errors.New("timeout reached while parsing the file")
+	ErrJobNotFound      = errors.New("the parsing job does not exist or has expired")
+	ErrResultTooLarge   = errors.New("the result exceeds the maximum allowed size")
+	ErrZipEntryTooLarge = errors.New("the ZIP entry exceeds the maximum allowed size")
+	ErrPollingStopped   = errors.New("polling stopped before the parsing finished")
+	ErrPartialSuccess   = errors.New("only part of the file was parsed")
+
+	ErrUnsupportedMimeType = errors.New("the MIME type is not supported by LlamaParse")
+	ErrUnknownPreset       = errors.New("unknown parse preset")
+	ErrConflictingPresets  = errors.New("only one parse preset can be used at a time")
+	ErrInvalidTargetPages  = errors.New("invalid target pages")
+	ErrUnsupportedLanguage = errors.New("the language is not supported by LlamaParse")
+	ErrInvalidWebhookURL   = errors.New("the webhook URL has to be an absolute http or https URL")
+	ErrRangeNotSupported   = errors.New("the result endpoint does not support range requests")
+	ErrNoPreviousResult    = errors.New("there is no previous result to reparse the pages of")
+	ErrInvalidPaths        = errors.New("the endpoint path has to have one %s for each value it is given")
+	ErrInvalidBoundary     = errors.New("the multipart boundary has to be 1 to 70 characters allowed by RFC 2046, not ending in a space")
 
 	// sos: https://github.com/run-llama/llama_parse/blob/7515fe5f3ef6757a1859274c1148a56b26254357/llama_parse/utils.py#L102C1-L193C2 + utils/extension_to_mime.py
 	SUPPORTED_MIME_TYPES = []string{"application/pdf", "image/cgm", "application/msword", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/vnd.ms-word.document.macroEnabled.12", "text/vnd.graphviz", "application/vnd.ms-word.template.macroEnabled.12", "application/vnd.lotus-wordpro", "application/vnd.apple.pages", "application/vnd.powerbuilder6", "application/vnd.ms-powerpoint", "application/vnd.ms-powerpoint.presentation.macroEnabled.12", "application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/vnd.ms-powerpoint", "application/vnd.ms-powerpoint.template.macroEnabled.12", "application/vnd.openxmlformats-officedocument.presentationml.template", "application/rtf", "application/sdp", "application/vnd.sun.xml.impress.template", "application/vnd.sun.xml.impress", "application/vnd.sun.xml.writer", "application/vnd.sun.xml.writer.template", "application/vnd.sun.xml.writer.global", "text/plain", "application/vnd.wordperfect", "application/vnd.ms-works", "text/xml", "application/epub+zip", "image/jpeg", "image/jpeg", "image/png", "image/gif", "image/bmp", "image/svg+xml", "image/tiff", "image/webp", "text/html", "text/html", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/vnd.ms-excel", "application/vnd.ms-excel.sheet.macroEnabled.12", "application/vnd.ms-excel.sheet.binary.macroEnabled.12", "application/vnd.ms-excel", "text/csv", "application/vnd.apple.numbers", "application/vnd.oasis.opendocument.spreadsheet", "application/vnd.dbf", "application/vnd.lotus-1-2-3", "application/vnd.lotus-1-2-3", "application/vnd.lotus-1-2-3", "application/vnd.ms-works", "application/vnd.lotus-1-2-3", "text/tab-separated-values"}
+
+	// The extension uploads of each supported MIME type get, LlamaParse tells formats apart by it.
+	// mime.ExtensionsByType sorts its extensions, so it would pick .htm over .html or .asc over .txt.
+	MIME_TYPE_EXTENSIONS = map[string]string{
+		"application/pdf":    ".pdf",
+		"image/cgm":          ".cgm",
+		"application/msword": ".doc",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+		"application/vnd.ms-word.document.macroEnabled.12":                        ".docm",
+		"text/vnd.graphviz": ".dot",
+		"application/vnd.ms-word.template.macroEnabled.12":                          ".dotm",
+		"application/vnd.lotus-wordpro":                                             ".lwp",
+		"application/vnd.apple.pages":                                               ".pages",
+		"application/vnd.powerbuilder6":                                             ".pbd",
+		"application/vnd.ms-powerpoint":                                             ".ppt",
+		"application/vnd.ms-powerpoint.presentation.macroEnabled.12":                ".pptm",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+		"application/vnd.ms-powerpoint.template.macroEnabled.12":                    ".potm",
+		"application/vnd.openxmlformats-officedocument.presentationml.template":     ".potx",
+		"application/rtf":                          ".rtf",
+		"application/sdp":                          ".sdp",
+		"application/vnd.sun.xml.impress.template": ".sti",
+		"application/vnd.sun.xml.impress":          ".sxi",
+		"application/vnd.sun.xml.writer":           ".sxw",
+		"application/vnd.sun.xml.writer.template":  ".stw",
+		"application/vnd.sun.xml.writer.global":    ".sxg",
+		"text/plain":                               ".txt",
+		"application/vnd.wordperfect":              ".wpd",
+		"application/vnd.ms-works":                 ".wps",
+		"text/xml":                                 ".xml",
+		"application/epub+zip":                     ".epub",
+		"image/jpeg":                               ".jpg",
+		"image/png":                                ".png",
+		"image/gif":                                ".gif",
+		"image/bmp":                                ".bmp",
+		"image/svg+xml":                            ".svg",
+		"image/tiff":                               ".tiff",
+		"image/webp":                               ".webp",
+		"text/html":                                ".html",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
+		"application/vnd.ms-excel":                              ".xls",
+		"application/vnd.ms-excel.sheet.macroEnabled.12":        ".xlsm",
+		"application/vnd.ms-excel.sheet.binary.macroEnabled.12": ".xlsb",
+		"text/csv":                      ".csv",
+		"application/vnd.apple.numbers": ".numbers",
+		"application/vnd.oasis.opendocument.spreadsheet": ".ods",
+		"application/vnd.dbf":                            ".dbf",
+		"application/vnd.lotus-1-2-3":                    ".123",
+		"text/tab-separated-values":                      ".tsv",
+	}
 )
 
-func createMultipartRequest(file []byte, language *string) (*bytes.Buffer, string, error) {
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// createFormFile is multipart.Writer.CreateFormFile with an explicit Content-Type instead of application/octet-stream.
+func createFormFile(writer *multipart.Writer, fieldName string, fileName string, contentType string) (io.Writer, error) {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+
+	return writer.CreatePart(header)
+}
+
+// formField is a form field sent along with the file, such as the language.
+type formField struct {
+	name  string
+	value string
+}
+
+// An empty fileName defaults to "uploadfile". The file is copied through a buffer of bufferSize bytes.
+func writeMultipartForm(writer *multipart.Writer, file io.Reader, fileName string, mimeType string, fields []formField, bufferSize int) error {
+	if fileName == "" {
+		fileName = "uploadfile"
+	}
+
+	part, err := createFormFile(writer, "file", fileName, mimeType)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyBuffer(part, file, make([]byte, bufferSize))
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		err = writer.WriteField(field.name, field.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// An empty boundary is a random one.
+func createMultipartRequest(file io.Reader, fileName string, mimeType string, fields []formField, bufferSize int, boundary string) (*bytes.Buffer, string, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
+	if boundary != "" {
+		err := writer.SetBoundary(boundary)
+		if err != nil {
+			return nil, "", err
+		}
+	}
 
-	part, err := writer.CreateFormFile("file", "uploadfile")
+	err := writeMultipartForm(writer, file, fileName, mimeType, fields, bufferSize)
 	if err != nil {
 		return nil, "", err
 	}
 
-	_, err = part.Write(file)
+	return body, writer.FormDataContentType(), nil
+}
+
+// streamMultipartRequest writes the multipart body while it is being sent, so the file is never held in memory.
+// The request has no Content-Length and is sent chunked. An empty boundary is a random one.
+// done is closed once the body stopped reading file, after it was written whole or its reader was closed.
+func streamMultipartRequest(file io.Reader, fileName string, mimeType string, fields []formField, bufferSize int, boundary string) (body io.ReadCloser, contentType string, done <-chan struct{}, err error) {
+	reader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	if boundary != "" {
+		err = writer.SetBoundary(boundary)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		pipeWriter.CloseWithError(writeMultipartForm(writer, file, fileName, mimeType, fields, bufferSize))
+	}()
+
+	return reader, writer.FormDataContentType(), finished, nil
+}
+
+// replayStream sets req.GetBody to stream file again from where it is now, so an upload from a file that can be rewound is retried like an in-memory one.
+// done is the one of the body req was created with. A new body is only written once the previous one stopped reading file.
+func replayStream(req *http.Request, file io.ReadSeeker, done <-chan struct{}, fileName string, mimeType string, fields []formField, bufferSize int, boundary string) {
+	start, err := file.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return nil, "", err
+		return
 	}
 
-	if language != nil {
-		err = writer.WriteField("language", *language)
+	body := req.Body
+	req.GetBody = func() (io.ReadCloser, error) {
+		body.Close()
+		<-done
+
+		_, err := file.Seek(start, io.SeekStart)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
+
+		var streamed io.ReadCloser
+		streamed, _, done, err = streamMultipartRequest(file, fileName, mimeType, fields, bufferSize, boundary)
+		if err != nil {
+			return nil, err
+		}
+		body = streamed
+
+		return streamed, nil
 	}
+}
 
-	contentType := writer.FormDataContentType()
-	err = writer.Close()
+// readResultBody reads a result body of at most the client's maxResultSize bytes.
+func (c *Client) readResultBody(body io.Reader) ([]byte, error) {
+	if c.maxResultSize <= 0 {
+		return io.ReadAll(body)
+	}
+
+	result, err := io.ReadAll(io.LimitReader(body, c.maxResultSize+1))
 	if err != nil {
-		return nil, "", err
+		return nil, err
+	}
+
+	if int64(len(result)) > c.maxResultSize {
+		return nil, ErrResultTooLarge
+	}
+
+	return result, nil
+}
+
+// detectMimeType takes the MIME type from the extension of fileName, or from the file contents if it has none.
+// Content sniffing sees office documents as zip archives, so the extension is more telling.
+func detectMimeType(file []byte, fileName string) string {
+	mimeType := mime.TypeByExtension(filepath.Ext(fileName))
+	if mimeType == "" {
+		return http.DetectContentType(file)
 	}
 
-	return body, contentType, nil
+	return mimeType
 }
 
-func getJobResult(apiKey string, baseUrl string, jobID string, mode LlamaParseMode, timeout time.Duration, checkInterval time.Duration) (string, error) {
-	client := &http.Client{Timeout: timeout}
-	headers := map[string]string{
-		"Authorization": "Bearer " + apiKey,
+func mimeTypeFromName(fileName string) string {
+	mimeType := mime.TypeByExtension(filepath.Ext(fileName))
+	if mimeType == "" {
+		return "application/octet-stream"
 	}
-	statusURL := fmt.Sprintf("%s/api/parsing/job/%s", baseUrl, jobID)
-	resultURL := fmt.Sprintf("%s/api/parsing/job/%s/result/%s", baseUrl, jobID, mode)
 
-	start := time.Now()
-	for {
-		if time.Since(start) > timeout {
-			return "", ErrTimeoutReached
+	return mimeType
+}
+
+func resolveTimeouts(timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (time.Duration, time.Duration) {
+	timeoutSeconds := DEFAULT_MAX_TIMEOUT_SECONDS
+	if timeoutSecondsOptional != nil {
+		timeoutSeconds = *timeoutSecondsOptional
+	}
+
+	checkIntervalSeconds := DEFAULT_CHECK_INTERVAL_SECONDS
+	if checkIntervalSecondsOptional != nil {
+		checkIntervalSeconds = *checkIntervalSecondsOptional
+	}
+
+	return time.Duration(timeoutSeconds) * time.Second, time.Duration(checkIntervalSeconds) * time.Second
+}
+
+// transport is shared by all requests so connections to LlamaCloud are reused across calls.
+// It is http.DefaultTransport with more idle connections kept per host, as every request goes to the same one.
+var transport = func() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 16
+	transport.ForceAttemptHTTP2 = true
+
+	return transport
+}()
+
+// sleep is time.Sleep that returns early with ctx.Err() when ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// With stream set, the file is read while uploading instead of being buffered first.
+func (c *Client) submitJob(ctx context.Context, file io.Reader, fileName string, mimeType string, language *string, stream bool) (string, error) {
+	fields := c.formFields(language)
+
+	var body io.Reader
+	var contentType string
+	var done <-chan struct{}
+	var err error
+	if stream {
+		body, contentType, done, err = streamMultipartRequest(file, fileName, mimeType, fields, c.uploadBufferSize, c.multipartBoundary)
+	} else {
+		body, contentType, err = createMultipartRequest(file, fileName, mimeType, fields, c.uploadBufferSize, c.multipartBoundary)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	req, err := c.newRequest(ctx, "POST", c.paths.Upload, body)
+	if err != nil {
+		// without a request nothing reads the streamed body, closing it stops the goroutine writing it
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
 		}
+		return "", err
+	}
 
-		time.Sleep(checkInterval)
+	req.Header.Set("Content-Type", contentType)
+	if seeker, ok := file.(io.ReadSeeker); ok && stream {
+		// the retry has to use the same boundary, the Content-Type is only set once
+		_, params, _ := mime.ParseMediaType(contentType)
+		replayStream(req, seeker, done, fileName, mimeType, fields, c.uploadBufferSize, params["boundary"])
+	}
+	if c.onUploadProgress != nil {
+		trackUploadProgress(req, c.onUploadProgress)
+	}
 
-		req, err := http.NewRequest("GET", statusURL, nil)
-		if err != nil {
-			return "", err
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var response uploadResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return "", err
+	}
+
+	if response.ID == "" {
+		message := response.message()
+		if message != "" {
+			return "", fmt.Errorf("%w: %s", ErrParsingFailed, message)
 		}
-		for key, value := range headers {
-			req.Header.Set(key, value)
+		return "", ErrParsingFailed
+	}
+
+	return response.ID, nil
+}
+
+// Statuses after which a job doesn't change anymore.
+var terminalStatuses = []string{"SUCCESS", "PARTIAL_SUCCESS", "ERROR", "FAILED", "CANCELLED"}
+
+// getJobStatus returns an empty status when the status endpoint fails in a way that may go away, so the caller can try again.
+func (c *Client) getJobStatus(ctx context.Context, jobID string) (statusResponse, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf(c.paths.JobStatus, url.PathEscape(jobID)), nil)
+	if err != nil {
+		return statusResponse{}, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return statusResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return statusResponse{}, ErrJobNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return statusResponse{}, newAPIError(resp)
+	}
+
+	var response statusResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return statusResponse{}, err
+	}
+
+	return response, nil
+}
+
+// missedCheck reports whether a status check that failed with statusCode only missed the status for now.
+// Client errors such as a revoked key would only come up again on every poll.
+func missedCheck(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout || retryableStatus(statusCode)
+}
+
+// waitForJob polls the job until it reaches a terminal status and returns it.
+// submittedAt is the time the job was submitted, the timeout is counted from it rather than from the first poll.
+func (c *Client) waitForJob(ctx context.Context, jobID string, submittedAt time.Time, poll PollStrategy) (statusResponse, error) {
+	// a status check running into the timeout is cut short instead of taking the whole request timeout
+	parent := ctx
+	ctx, cancel := context.WithDeadline(ctx, submittedAt.Add(c.timeout))
+	defer cancel()
+
+	var status statusResponse
+	for attempt := 0; ; attempt++ {
+		if time.Since(submittedAt) > c.timeout {
+			return statusResponse{}, ErrTimeoutReached
 		}
 
-		resp, err := client.Do(req)
+		wait, ok := poll.Next(attempt, status.Status)
+		if !ok {
+			return statusResponse{}, ErrPollingStopped
+		}
+		if attempt == 0 {
+			wait = max(wait, c.initialDelay)
+		}
+		err := sleep(ctx, wait)
 		if err != nil {
-			return "", err
+			return statusResponse{}, timeoutError(parent, err)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			continue
+		status, err = c.getJobStatus(ctx, jobID)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && missedCheck(apiErr.StatusCode) {
+			// the job is still there, it is checked again at the next poll
+			err = nil
 		}
-
-		var statusResponse map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&statusResponse)
 		if err != nil {
-			return "", err
+			return statusResponse{}, timeoutError(parent, err)
 		}
 
-		status, ok := statusResponse["status"].(string)
-		if !ok || status != "SUCCESS" {
-			continue
+		if c.onProgress != nil {
+			c.onProgress(Progress{
+				JobID:   jobID,
+				Status:  status.Status,
+				Elapsed: time.Since(submittedAt),
+			})
 		}
 
-		req, err = http.NewRequest("GET", resultURL, nil)
+		if slices.Contains(terminalStatuses, status.Status) {
+			return status, nil
+		}
+	}
+}
+
+// getJobResultBytes waits for the job and fetches its result.
+// For PARTIAL_SUCCESS it returns the result of the pages that were parsed together with a *JobError wrapping ErrPartialSuccess, unless the client is strict.
+func (c *Client) getJobResultBytes(ctx context.Context, jobID string, mode LlamaParseMode, submittedAt time.Time) ([]byte, error) {
+	status, err := c.waitForJob(ctx, jobID, submittedAt, c.poll())
+	if err != nil {
+		return nil, err
+	}
+
+	jobErr := &JobError{
+		JobID:   jobID,
+		Status:  status.Status,
+		Message: status.message(),
+		err:     ErrParsingFailed,
+	}
+
+	if c.resultGracePeriod > 0 && (status.Status == "SUCCESS" || status.Status == "PARTIAL_SUCCESS") {
+		err = sleep(ctx, c.resultGracePeriod)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		for key, value := range headers {
-			req.Header.Set(key, value)
+	}
+
+	switch status.Status {
+	case "SUCCESS":
+		return c.getResultBytes(ctx, jobID, mode)
+	case "PARTIAL_SUCCESS":
+		if c.strict {
+			return nil, jobErr
 		}
 
-		resp, err = client.Do(req)
+		result, err := c.getResultBytes(ctx, jobID, mode)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			return "", ErrParsingFailed
+		jobErr.err = ErrPartialSuccess
+		return result, jobErr
+	default:
+		return nil, jobErr
+	}
+}
+
+// decodeJobResult decodes the result of parseFile or parseReader, keeping the content of a partially parsed file along with its error.
+// Markdown and text results are post-processed as the client's options ask.
+func (c *Client) decodeJobResult(ctx context.Context, jobID string, resultBytes []byte, mode LlamaParseMode, err error) (string, error) {
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return "", err
+	}
+
+	content, decodeErr := decodeResult(resultBytes, mode)
+	if decodeErr != nil {
+		return "", decodeErr
+	}
+
+	if (mode == MARKDOWN || mode == TEXT) && c.stripRepeatedHeaders {
+		content, decodeErr = c.stripJobHeaders(ctx, jobID, mode)
+		if decodeErr != nil {
+			return "", decodeErr
+		}
+	}
+	if mode == MARKDOWN && c.mergeTables {
+		content = MergeTables(content, DEFAULT_PAGE_SEPARATOR)
+	}
+	if mode == MARKDOWN && c.inlineImages {
+		content, decodeErr = c.inlineJobImages(ctx, jobID, content)
+		if decodeErr != nil {
+			return "", decodeErr
 		}
+	}
 
-		var resultResponse map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&resultResponse)
-		if err != nil {
-			return "", err
+	return content, err
+}
+
+// getResultBytes fetches the result of a job that already finished. A result without content for mode counts as not ready yet.
+func (c *Client) getResultBytes(ctx context.Context, jobID string, mode LlamaParseMode) ([]byte, error) {
+	// The result can lag behind the status for a moment, so give it a few tries before giving up.
+	for retry := 0; ; retry++ {
+		result, err := c.fetchResult(ctx, jobID, mode)
+		if err == nil {
+			_, err = decodeResult(result, mode)
+		}
+		if err == nil {
+			return result, nil
+		}
+		// an error status was already retried by do where it makes sense, only a missing or incomplete result is retried here
+		var apiErr *APIError
+		if !errors.Is(err, ErrParsingFailed) || errors.As(err, &apiErr) || retry >= RESULT_FETCH_RETRIES {
+			return nil, err
 		}
 
-		result, ok := resultResponse[string(mode)].(string)
-		if !ok {
-			return "", ErrParsingFailed
+		err = sleep(ctx, RESULT_FETCH_RETRY_DELAY)
+		if err != nil {
+			return nil, err
 		}
+	}
+}
+
+func (c *Client) fetchResult(ctx context.Context, jobID string, mode LlamaParseMode) ([]byte, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf(c.paths.JobResult, url.PathEscape(jobID), mode), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	result, err := c.readResultBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(result) {
+		return nil, ErrParsingFailed
+	}
+
+	return result, nil
+}
+
+// parseFile uploads the file and waits for the raw result. It returns the job ID alongside the result.
+// An empty mimeType is detected with detectMimeType.
+func (c *Client) parseFile(ctx context.Context, file []byte, fileName string, mimeType string, mode LlamaParseMode, language *string) (string, []byte, error) {
+	jobID, result, _, err := c.parseFileFallback(ctx, file, fileName, mimeType, mode, language)
+	return jobID, result, err
+}
+
+// parseFileFallback is parseFile also reporting whether the file was parsed again with the fallback options after its job failed.
+func (c *Client) parseFileFallback(ctx context.Context, file []byte, fileName string, mimeType string, mode LlamaParseMode, language *string) (string, []byte, bool, error) {
+	if len(file) == 0 && !c.allowEmptyFile {
+		return "", nil, false, ErrEmptyFile
+	}
+
+	if mimeType == "" {
+		mimeType = detectMimeType(file, fileName)
+	}
+
+	jobID, result, err := c.parseUpload(ctx, bytes.NewReader(file), fileName, mimeType, false, mode, language)
+
+	var jobErr *JobError
+	if c.fallback == nil || !errors.As(err, &jobErr) || (jobErr.Status != "ERROR" && jobErr.Status != "FAILED") {
+		return jobID, result, false, err
+	}
+
+	fallback := c.with(c.fallback)
+	fallback.fallback = nil
+	jobID, result, err = fallback.parseUpload(ctx, bytes.NewReader(file), fileName, mimeType, false, mode, language)
+	return jobID, result, true, err
+}
+
+// parseReader is parseFile for files that are streamed while uploading.
+func (c *Client) parseReader(ctx context.Context, file io.Reader, fileName string, mimeType string, mode LlamaParseMode, language *string) (string, []byte, error) {
+	return c.parseUpload(ctx, file, fileName, mimeType, true, mode, language)
+}
+
+func (c *Client) parseUpload(ctx context.Context, file io.Reader, fileName string, mimeType string, stream bool, mode LlamaParseMode, language *string) (string, []byte, error) {
+	err := c.check()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if c.mimeAutoCorrect {
+		file, fileName, mimeType = correctFileType(file, fileName, mimeType)
+	}
+
+	submittedAt := time.Now()
 
-		return result, nil
+	// the deadline keeps retried requests within the timeout too
+	parent := ctx
+	ctx, cancel := c.withDeadline(ctx, submittedAt.Add(c.timeout))
+	defer cancel()
+
+	jobID, err := c.submitJob(ctx, file, fileName, mimeType, language, stream)
+	if err != nil {
+		return "", nil, timeoutError(parent, err)
+	}
+
+	result, err := c.getJobResultBytes(ctx, jobID, mode, submittedAt)
+	if err != nil && result == nil {
+		return "", nil, timeoutError(parent, err)
+	}
+
+	return jobID, result, err
+}
+
+// withDeadline bounds ctx by the earliest of its own deadline, the client's WithDeadline and deadline. Zero times don't bound it.
+func (c *Client) withDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() || (!c.deadline.IsZero() && c.deadline.Before(deadline)) {
+		deadline = c.deadline
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
 	}
+
+	// a parent deadline that is earlier is kept
+	return context.WithDeadline(ctx, deadline)
+}
+
+// timeoutError turns running into the deadline of the parse into ErrTimeoutReached, unless the caller's own context ran out.
+func timeoutError(parent context.Context, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+		return ErrTimeoutReached
+	}
+
+	return err
 }
 
 /*
@@ -150,86 +818,390 @@ Args:
 	mode: The output format (markdown, text, json).
 	apiKeyOptional: The LlamaCloud API key. If not provided, it will be read from the LLAMA_CLOUD_API_KEY environment variable.
 	languageOptional: The language of the file. If not provided, it will be detected automatically.
-	timeoutSecondsOptional: The maximum time to wait for the parsing to finish. Default is 2000 seconds.
-	checkIntervalSecondsOptional: The interval between checking the parsing status. Default is 1 second.
+	timeoutSecondsOptional: The maximum time to wait for the parsing to finish. Default is 2000 seconds, including the upload. Each HTTP request is additionally limited to 120 seconds.
+	checkIntervalSecondsOptional: The interval before the first check of the parsing status. Default is 1 second. It doubles after every check, up to 10 seconds.
 
 Returns:
 
-	The parsed file.
+	The parsed file. In JSON mode it is the whole JSON result document, which DecodeResult can decode.
+	A job that failed returns a *JobError. If only part of the file was parsed, the parsed part is returned along with a *JobError wrapping ErrPartialSuccess.
 */
 func Parse(file []byte, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
-	if len(file) == 0 {
-		return "", ErrEmptyFile
-	}
+	return ParseContext(context.Background(), file, mode, apiKeyOptional, languageOptional, timeoutSecondsOptional, checkIntervalSecondsOptional)
+}
 
-	var apiKey string
+/*
+ParseContext is Parse with a context. Cancelling ctx stops the upload or the polling and returns ctx.Err().
 
-	if apiKeyOptional != nil {
-		apiKey = *apiKeyOptional
-	} else {
-		apiKey = os.Getenv("LLAMA_CLOUD_API_KEY")
-		if apiKey == "" {
-			return "", ErrNoAPIKey
+Args:
+
+	ctx: The context of the upload and every status check.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed file.
+*/
+func ParseContext(ctx context.Context, file []byte, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseContext(ctx, file, mode, languageOptional)
+}
+
+/*
+ParseBytes parses a file using the LlamaParse API and returns the raw body of the result endpoint.
+
+Unlike Parse, the response is not decoded, so it can be handed straight to json.Unmarshal. This is the only way to get a usable result in JSON mode.
+
+Args:
+
+	The same as Parse.
+
+Returns:
+
+	The raw JSON result document, e.g. {"markdown": "...", "job_metadata": {...}} in markdown mode.
+*/
+func ParseBytes(file []byte, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) ([]byte, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseBytes(context.Background(), file, mode, languageOptional)
+}
+
+func (c *Client) parseBytes(ctx context.Context, file []byte, mode LlamaParseMode, language *string) ([]byte, error) {
+	_, resultBytes, err := c.parseFile(ctx, file, c.fileName, "", mode, language)
+	return resultBytes, err
+}
+
+/*
+ParseString parses in-memory content, such as scraped HTML, using the LlamaParse API.
+
+Args:
+
+	content: The content to parse.
+	mimeType: The MIME type of the content, e.g. text/html. It has to be one of SUPPORTED_MIME_TYPES, unless WithInputConverter converts it to one.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed content.
+*/
+func ParseString(content string, mimeType string, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseString(context.Background(), content, mimeType, mode, languageOptional)
+}
+
+func (c *Client) parseString(ctx context.Context, content string, mimeType string, mode LlamaParseMode, language *string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil || !slices.Contains(SUPPORTED_MIME_TYPES, mediaType) {
+		if c.inputConverter == nil {
+			return "", ErrUnsupportedMimeType
+		}
+
+		converted, convertedType, err := c.inputConverter([]byte(content), mimeType)
+		if err != nil {
+			return "", err
 		}
+
+		mediaType, _, err = mime.ParseMediaType(convertedType)
+		if err != nil || !slices.Contains(SUPPORTED_MIME_TYPES, mediaType) {
+			return "", ErrUnsupportedMimeType
+		}
+		content, mimeType = string(converted), convertedType
+	}
+
+	// LlamaParse looks at the file extension, so give the upload one that matches the MIME type.
+	fileName := "uploadfile"
+	if extension, ok := MIME_TYPE_EXTENSIONS[mediaType]; ok {
+		fileName += extension
+	} else if extensions, err := mime.ExtensionsByType(mediaType); err == nil && len(extensions) > 0 {
+		// a type added to SUPPORTED_MIME_TYPES without an extension here
+		fileName += extensions[0]
 	}
 
-	url := fmt.Sprintf("%s/api/parsing/upload", BASE_URL)
+	jobID, resultBytes, err := c.parseFile(ctx, []byte(content), fileName, mimeType, mode, language)
+	return c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+}
+
+/*
+ParseDocuments parses a file in JSON mode and returns one LlamaIndex Document per page.
+
+Args:
+
+	The same as Parse, without the mode.
+
+Returns:
+
+	The documents, each with the page text and the page number and job ID as metadata.
+	If only part of the file was parsed, the documents of the parsed pages come with a *JobError wrapping ErrPartialSuccess.
+*/
+func ParseDocuments(file []byte, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) ([]Document, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseDocuments(context.Background(), file, languageOptional)
+}
+
+func (c *Client) parseDocuments(ctx context.Context, file []byte, language *string) ([]Document, error) {
+	jobID, resultBytes, err := c.parseFile(ctx, file, c.fileName, "", JSON, language)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	result, decodeErr := DecodeResult(resultBytes)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	documents := make([]Document, 0, len(result.Pages))
+	for _, page := range result.Pages {
+		documents = append(documents, Document{
+			Text: page.Text,
+			Metadata: map[string]any{
+				"page":   page.Page,
+				"job_id": jobID,
+			},
+		})
+	}
+
+	return documents, err
+}
+
+/*
+ParseDetailed parses a file using the LlamaParse API and returns the result with the job details.
+
+Args:
 
-	body, contentType, err := createMultipartRequest(file, languageOptional)
+	The same as Parse.
+
+Returns:
+
+	The parsed file, its job ID and status, whether it was served from the cache and the job metadata, which has the page count and credits used.
+	If only part of the file was parsed, the result comes with a *JobError wrapping ErrPartialSuccess.
+*/
+func ParseDetailed(file []byte, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (*Result, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseDetailed(context.Background(), file, mode, languageOptional)
+}
+
+func (c *Client) parseDetailed(ctx context.Context, file []byte, mode LlamaParseMode, language *string) (*Result, error) {
+	jobID, resultBytes, fellBack, err := c.parseFileFallback(ctx, file, c.fileName, "", mode, language)
+	content, err := c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	status := "SUCCESS"
+	var jobErr *JobError
+	if errors.As(err, &jobErr) {
+		status = jobErr.Status
+	}
+
+	var resultResponse struct {
+		JobMetadata JobMetadata `json:"job_metadata"`
+	}
+	jsonErr := json.Unmarshal(resultBytes, &resultResponse)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	result := &Result{
+		Content:  content,
+		JobID:    jobID,
+		Status:   status,
+		Cached:   resultResponse.JobMetadata.JobIsCacheHit,
+		Metadata: resultResponse.JobMetadata,
+		Fallback: fellBack,
+	}
+
+	if result.Cached && c.validateCached != nil && !c.validateCached(result) {
+		// the parse without the cache is taken as it is, it can't be stale
+		uncached := c.with([]Option{WithDoNotCache(true), WithValidateCached(nil)})
+		return uncached.parseDetailed(ctx, file, mode, language)
+	}
+
+	return result, err
+}
+
+/*
+ParseMultipartFile parses a file received in a multipart upload, e.g. from http.Request.FormFile, using the LlamaParse API.
+
+The file is streamed into the upload as it is sent, so it is never held in memory as a whole.
+
+Args:
+
+	file: The uploaded file.
+	header: Its header, the file name and Content-Type are passed on to LlamaParse.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed file.
+*/
+func ParseMultipartFile(file multipart.File, header *multipart.FileHeader, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseMultipartFile(context.Background(), file, header, mode, languageOptional)
+}
+
+func (c *Client) parseMultipartFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, mode LlamaParseMode, language *string) (string, error) {
+	if header.Size == 0 && !c.allowEmptyFile {
+		return "", ErrEmptyFile
+	}
+
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = mimeTypeFromName(header.Filename)
+	}
+
+	jobID, resultBytes, err := c.parseReader(ctx, file, header.Filename, mimeType, mode, language)
+	return c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+}
+
+/*
+ParseFS parses a file from a filesystem, such as an embed.FS, using the LlamaParse API.
+
+Args:
+
+	fsys: The filesystem to read from.
+	name: The path of the file in fsys. Its extension determines the MIME type.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed file.
+*/
+func ParseFS(fsys fs.FS, name string, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseFS(context.Background(), fsys, name, mode, languageOptional)
+}
+
+func (c *Client) parseFS(ctx context.Context, fsys fs.FS, name string, mode LlamaParseMode, language *string) (string, error) {
+	file, err := fsys.Open(name)
 	if err != nil {
 		return "", err
 	}
+	defer file.Close()
 
-	req, err := http.NewRequest("POST", url, body)
+	info, err := file.Stat()
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", contentType)
+	if info.Size() == 0 && !c.allowEmptyFile {
+		return "", ErrEmptyFile
+	}
 
-	var timeoutSeconds int
+	jobID, resultBytes, err := c.parseReader(ctx, file, path.Base(name), mimeTypeFromName(name), mode, language)
+	return c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+}
 
-	if timeoutSecondsOptional != nil {
-		timeoutSeconds = *timeoutSecondsOptional
-	} else {
-		timeoutSeconds = DEFAULT_MAX_TIMEOUT_SECONDS
+/*
+WaitForCompletion waits for a parsing job to finish without fetching its result.
+
+Args:
+
+	jobID: The ID of the job.
+	submittedAtOptional: When the job was submitted, the timeout counts from then, so a job waited on after being submitted elsewhere doesn't get a fresh one. If not provided, it counts from now.
+	apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional: The same as in Parse.
+
+Returns:
+
+	The final status of the job: SUCCESS, PARTIAL_SUCCESS, ERROR, FAILED or CANCELLED.
+*/
+func WaitForCompletion(jobID string, submittedAtOptional *time.Time, apiKeyOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (string, error) {
+	var submittedAt time.Time
+	if submittedAtOptional != nil {
+		submittedAt = *submittedAtOptional
 	}
 
-	client := &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second}
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).waitForCompletion(context.Background(), jobID, submittedAt)
+}
 
-	resp, err := client.Do(req)
+// A zero submittedAt counts the timeout from now.
+func (c *Client) waitForCompletion(ctx context.Context, jobID string, submittedAt time.Time) (string, error) {
+	err := c.check()
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", ErrParsingFailed
+	if submittedAt.IsZero() {
+		submittedAt = time.Now()
 	}
 
-	var response map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	parent := ctx
+	ctx, cancel := c.withDeadline(ctx, time.Time{})
+	defer cancel()
+
+	status, err := c.waitForJob(ctx, jobID, submittedAt, c.poll())
 	if err != nil {
-		return "", err
+		return "", timeoutError(parent, err)
 	}
 
-	jobID, ok := response["id"].(string)
-	if !ok {
-		return "", ErrParsingFailed
+	return status.Status, nil
+}
+
+/*
+GetResultRange fetches part of a finished job's result using an HTTP range request.
+
+The range applies to the raw result document, the same bytes ParseBytes returns, not to the decoded content.
+
+Args:
+
+	jobID: The ID of the job.
+	mode: The output format (markdown, text, json).
+	start, end: The first and last byte to fetch, both inclusive.
+	apiKeyOptional: The same as in Parse.
+
+Returns:
+
+	The requested bytes, or ErrRangeNotSupported if the server answered with the whole result.
+*/
+func GetResultRange(jobID string, mode LlamaParseMode, start int64, end int64, apiKeyOptional *string) ([]byte, error) {
+	return clientFromOptional(apiKeyOptional, nil, nil).getResultRange(context.Background(), jobID, mode, start, end)
+}
+
+func (c *Client) getResultRange(ctx context.Context, jobID string, mode LlamaParseMode, start int64, end int64) ([]byte, error) {
+	err := c.check()
+	if err != nil {
+		return nil, err
 	}
 
-	var checkIntervalSeconds int
-	if checkIntervalSecondsOptional != nil {
-		checkIntervalSeconds = *checkIntervalSecondsOptional
-	} else {
-		checkIntervalSeconds = DEFAULT_CHECK_INTERVAL_SECONDS
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf(c.paths.JobResult, url.PathEscape(jobID), mode), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := getJobResult(apiKey, BASE_URL, jobID, mode, time.Duration(timeoutSeconds)*time.Second, time.Duration(checkIntervalSeconds)*time.Second)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := c.do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return result, nil
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return c.readResultBody(resp.Body)
+	case http.StatusOK:
+		return nil, ErrRangeNotSupported
+	case http.StatusNotFound:
+		return nil, ErrJobNotFound
+	default:
+		return nil, newAPIError(resp)
+	}
+}
+
+/*
+ParseMarkdownAndText parses a file once and returns both its markdown and its plain text result.
+
+Args:
+
+	The same as Parse, without the mode.
+
+Returns:
+
+	The markdown and plain text of the parsed file. If only part of the file was parsed, they come with a *JobError wrapping ErrPartialSuccess.
+*/
+func ParseMarkdownAndText(file []byte, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) (*MarkdownAndText, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseMarkdownAndText(context.Background(), file, languageOptional)
+}
+
+func (c *Client) parseMarkdownAndText(ctx context.Context, file []byte, language *string) (*MarkdownAndText, error) {
+	results, err := c.parseMulti(ctx, file, []LlamaParseMode{MARKDOWN, TEXT}, language)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	return &MarkdownAndText{
+		Markdown: results[MARKDOWN],
+		Text:     results[TEXT],
+	}, err
 }
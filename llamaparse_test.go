@@ -0,0 +1,273 @@
+package llamaparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestJobStatuses(t *testing.T) {
+	tests := []struct {
+		status  string
+		opts    []Option
+		content string
+		err     error
+	}{
+		{status: "SUCCESS", content: "text"},
+		{status: "PARTIAL_SUCCESS", content: "text", err: ErrPartialSuccess},
+		{status: "PARTIAL_SUCCESS", opts: []Option{WithStrict(true)}, err: ErrParsingFailed},
+		{status: "ERROR", err: ErrParsingFailed},
+		{status: "FAILED", err: ErrParsingFailed},
+		{status: "CANCELLED", err: ErrParsingFailed},
+	}
+
+	for _, test := range tests {
+		name := test.status
+		if len(test.opts) > 0 {
+			name += " strict"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			server := newFakeServer(t)
+			server.status = func(job *fakeJob) string {
+				// the job is polled a few times before it finishes
+				if job.checks < 3 {
+					return "PENDING"
+				}
+				return test.status
+			}
+
+			content, err := server.client(test.opts...).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+			if content != test.content {
+				t.Errorf("got %q, want %q", content, test.content)
+			}
+
+			if test.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+			var jobErr *JobError
+			if !errors.As(err, &jobErr) || jobErr.Status != test.status || jobErr.JobID != "job-1" {
+				t.Errorf("got %#v, want a JobError for job-1 with %s", err, test.status)
+			}
+			if test.err == ErrPartialSuccess && errors.Is(err, ErrParsingFailed) {
+				t.Errorf("a partial success is also ErrParsingFailed: %v", err)
+			}
+		})
+	}
+}
+
+func TestPartialSuccessKeepsContent(t *testing.T) {
+	server := newFakeServer(t)
+	server.status = func(job *fakeJob) string {
+		return "PARTIAL_SUCCESS"
+	}
+	client := server.client(WithFilename("a.txt"))
+
+	both, err := client.ParseMarkdownAndText([]byte("text"))
+	if !errors.Is(err, ErrPartialSuccess) {
+		t.Fatalf("ParseMarkdownAndText: got %v, want ErrPartialSuccess", err)
+	}
+	if both == nil || both.Markdown != "text" || both.Text != "text" {
+		t.Errorf("ParseMarkdownAndText: got %+v, want the parsed part", both)
+	}
+
+	documents, err := client.ParseDocuments([]byte("page 1\fpage 2"))
+	if !errors.Is(err, ErrPartialSuccess) {
+		t.Fatalf("ParseDocuments: got %v, want ErrPartialSuccess", err)
+	}
+	if len(documents) != 2 || documents[0].Text != "page 1" || documents[1].Text != "page 2" {
+		t.Errorf("ParseDocuments: got %+v, want the parsed pages", documents)
+	}
+
+	chunks, err := client.ParseChunks([]byte("first\n\nsecond"), MARKDOWN, 6, 0)
+	if !errors.Is(err, ErrPartialSuccess) {
+		t.Fatalf("ParseChunks: got %v, want ErrPartialSuccess", err)
+	}
+	if !slices.Equal(chunks, []string{"first", "second"}) {
+		t.Errorf("ParseChunks: got %q, want the chunks of the parsed part", chunks)
+	}
+}
+
+// readFormFile reads back the file and fields of a multipart body.
+func readFormFile(t testing.TB, body io.Reader, contentType string) ([]byte, map[string][]string) {
+	t.Helper()
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer form.RemoveAll()
+
+	file, err := form.File["file"][0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content, form.Value
+}
+
+func TestMultipartRequest(t *testing.T) {
+	file := bytes.Repeat([]byte("0123456789"), 1000)
+	fields := []formField{{"language", "en"}, {"target_pages", "0-3"}}
+
+	for _, bufferSize := range []int{1, 7, DEFAULT_UPLOAD_BUFFER_SIZE_BYTES} {
+		// hides bytes.Reader's WriteTo, so the file goes through the buffer
+		body, contentType, err := createMultipartRequest(struct{ io.Reader }{bytes.NewReader(file)}, "a.txt", "text/plain", fields, bufferSize, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, values := readFormFile(t, body, contentType)
+		if !bytes.Equal(content, file) || values["language"][0] != "en" || values["target_pages"][0] != "0-3" {
+			t.Errorf("buffer of %d: the form didn't round trip", bufferSize)
+		}
+
+		stream, contentType, _, err := streamMultipartRequest(struct{ io.Reader }{bytes.NewReader(file)}, "a.txt", "text/plain", fields, bufferSize, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, values = readFormFile(t, stream, contentType)
+		stream.Close()
+		if !bytes.Equal(content, file) || values["language"][0] != "en" || values["target_pages"][0] != "0-3" {
+			t.Errorf("buffer of %d: the streamed form didn't round trip", bufferSize)
+		}
+	}
+}
+
+func BenchmarkMultipartRequest(b *testing.B) {
+	file := bytes.Repeat([]byte{'x'}, 16*1024*1024)
+
+	for _, bufferSize := range []int{4 * 1024, DEFAULT_UPLOAD_BUFFER_SIZE_BYTES, 1024 * 1024} {
+		b.Run(fmt.Sprintf("buffered/%d", bufferSize), func(b *testing.B) {
+			b.SetBytes(int64(len(file)))
+			for range b.N {
+				_, _, err := createMultipartRequest(struct{ io.Reader }{bytes.NewReader(file)}, "a.pdf", "application/pdf", nil, bufferSize, "")
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("streamed/%d", bufferSize), func(b *testing.B) {
+			b.SetBytes(int64(len(file)))
+			for range b.N {
+				body, _, _, err := streamMultipartRequest(struct{ io.Reader }{bytes.NewReader(file)}, "a.pdf", "application/pdf", nil, bufferSize, "")
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = io.Copy(io.Discard, body)
+				if err != nil {
+					b.Fatal(err)
+				}
+				body.Close()
+			}
+		})
+	}
+}
+
+func TestWithValidateCached(t *testing.T) {
+	server := newFakeServer(t)
+	// the cache has an old result, the file parsed again has the new one
+	server.result = func(job *fakeJob, mode string) (int, string) {
+		if len(job.form["do_not_cache"]) == 0 {
+			return http.StatusOK, `{"text": "old", "job_metadata": {"job_is_cache_hit": true}}`
+		}
+		return http.StatusOK, `{"text": "new", "job_metadata": {"job_is_cache_hit": false}}`
+	}
+
+	validated := 0
+	validate := func(result *Result) bool {
+		validated++
+		return result.Content == "new"
+	}
+
+	result, err := server.client(WithValidateCached(validate)).ParseDetailed([]byte("file"), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "new" || result.Cached || result.JobID != "job-2" {
+		t.Errorf("got %+v, want the uncached result of job-2", result)
+	}
+	if validated != 1 {
+		t.Errorf("validated %d results, want only the cached one", validated)
+	}
+
+	// without a validation the cached result is kept
+	result, err = server.client().ParseDetailed([]byte("file"), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != "old" || !result.Cached {
+		t.Errorf("got %+v, want the cached result", result)
+	}
+	if got := server.jobs["job-3"].form["do_not_cache"]; len(got) != 0 {
+		t.Errorf("got do_not_cache %q without WithDoNotCache", got)
+	}
+}
+
+func TestWithInputConverter(t *testing.T) {
+	server := newFakeServer(t)
+	convert := func(file []byte, mimeType string) ([]byte, string, error) {
+		switch mimeType {
+		case "text/markdown":
+			return []byte("converted " + string(file)), "text/plain", nil
+		case "application/x-still-unsupported":
+			return file, mimeType, nil
+		}
+		return nil, "", errors.New("can't convert " + mimeType)
+	}
+	client := server.client(WithInputConverter(convert))
+
+	result, err := client.ParseString("# notes", "text/markdown", TEXT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "converted # notes" {
+		t.Errorf("got %q, want the converted content", result)
+	}
+	if got := server.jobs["job-1"].file; got != "converted # notes" {
+		t.Errorf("uploaded %q, want the converted content", got)
+	}
+
+	_, err = client.ParseString("x", "application/x-still-unsupported", TEXT)
+	if !errors.Is(err, ErrUnsupportedMimeType) {
+		t.Errorf("got %v for a conversion to an unsupported type, want ErrUnsupportedMimeType", err)
+	}
+
+	_, err = client.ParseString("x", "application/x-unknown", TEXT)
+	if err == nil || err.Error() != "can't convert application/x-unknown" {
+		t.Errorf("got %v, want the error of the converter", err)
+	}
+
+	_, err = server.client().ParseString("# notes", "text/markdown", TEXT)
+	if !errors.Is(err, ErrUnsupportedMimeType) {
+		t.Errorf("got %v without a converter, want ErrUnsupportedMimeType", err)
+	}
+
+	if uploads := server.uploadCount(); uploads != 1 {
+		t.Errorf("got %d uploads, want 1", uploads)
+	}
+}
@@ -0,0 +1,185 @@
+package llamaparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Page numbers and ranges separated by commas, e.g. "0,1,2-5".
+var targetPagesPattern = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+/*
+WithTargetPages only parses, and bills, the given pages of the file.
+
+Args:
+
+	pages: Page numbers and ranges separated by commas, e.g. "0,1,2-5". Pages are counted from 0 and ranges include both ends.
+		An empty string parses all pages. A malformed list makes the parse fail with ErrInvalidTargetPages before anything is uploaded.
+
+Returns:
+
+	The option.
+*/
+func WithTargetPages(pages string) Option {
+	return func(c *Client) {
+		pages = strings.ReplaceAll(pages, " ", "")
+		if pages == "" {
+			c.targetPages = ""
+			return
+		}
+
+		err := validateTargetPages(pages)
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		c.targetPages = pages
+	}
+}
+
+func validateTargetPages(pages string) error {
+	if !targetPagesPattern.MatchString(pages) {
+		return fmt.Errorf("%w: %q", ErrInvalidTargetPages, pages)
+	}
+
+	for _, pageRange := range strings.Split(pages, ",") {
+		first, last, isRange := strings.Cut(pageRange, "-")
+		if !isRange {
+			continue
+		}
+
+		// the pattern only lets digits through, so only an overflow can fail here
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidTargetPages, pages)
+		}
+		end, err := strconv.Atoi(last)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidTargetPages, pages)
+		}
+
+		if start > end {
+			return fmt.Errorf("%w: %q ends before it starts", ErrInvalidTargetPages, pageRange)
+		}
+	}
+
+	return nil
+}
+
+/*
+TargetPages formats page numbers for WithTargetPages, joining consecutive pages into ranges.
+
+Args:
+
+	pages: The pages to parse, counted from 0. They don't have to be sorted and duplicates are dropped.
+
+Returns:
+
+	The pages, e.g. "0-3,7" for 0, 1, 2, 3 and 7.
+*/
+func TargetPages(pages ...int) string {
+	pages = slices.Clone(pages)
+	slices.Sort(pages)
+	pages = slices.Compact(pages)
+
+	var ranges []string
+	for i := 0; i < len(pages); {
+		j := i
+		for j+1 < len(pages) && pages[j+1] == pages[j]+1 {
+			j++
+		}
+
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(pages[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", pages[i], pages[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(ranges, ",")
+}
+
+/*
+ReparsePages parses only some pages of a file again and puts them in place of the same pages of a previous result,
+e.g. to patch the few pages of a large document that came out wrong without paying for all of it again.
+
+Args:
+
+	file: The file previous was parsed from.
+	pages: The pages to parse again, as numbered in Page.Page, counted from 1.
+	previous: The JSON mode result to patch, e.g. from DecodeResult. It isn't modified. A nil previous fails with ErrNoPreviousResult.
+	opts: Options for this call only. WithTargetPages is replaced by pages.
+
+Returns:
+
+	A copy of previous with the pages replaced, sorted by page number, and numbered as in previous however LlamaParse numbered them. Its JobMetadata is the one of the reparse, so it only counts the reparsed pages.
+	If only some of the pages were parsed again, the result comes with a *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ReparsePages(file []byte, pages []int, previous *ParseResult, opts ...Option) (*ParseResult, error) {
+	if previous == nil {
+		return nil, ErrNoPreviousResult
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("%w: no pages to reparse", ErrInvalidTargetPages)
+	}
+
+	targets := make([]int, len(pages))
+	for i, page := range pages {
+		if page < 1 {
+			return nil, fmt.Errorf("%w: page %d", ErrInvalidTargetPages, page)
+		}
+		targets[i] = page - 1
+	}
+
+	c = c.with(opts).with([]Option{WithTargetPages(TargetPages(targets...))})
+
+	_, resultBytes, err := c.parseFile(context.Background(), file, c.fileName, "", JSON, nil)
+	if err != nil && !errors.Is(err, ErrPartialSuccess) {
+		return nil, err
+	}
+
+	reparsed, decodeErr := DecodeResult(resultBytes)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	// the result lists the pages in order, but may number them from the start of target_pages, so they are placed by position
+	numbers := slices.Clone(pages)
+	slices.Sort(numbers)
+	numbers = slices.Compact(numbers)
+	replacements := map[int]Page{}
+	for i, page := range reparsed.Pages {
+		if len(reparsed.Pages) == len(numbers) {
+			page.Page = numbers[i]
+		} else if !slices.Contains(numbers, page.Page) {
+			// a partial result can't be placed by position, only its pages numbered like the file are kept
+			continue
+		}
+		replacements[page.Page] = page
+	}
+
+	merged := &ParseResult{JobMetadata: reparsed.JobMetadata}
+	for _, page := range previous.Pages {
+		if replacement, ok := replacements[page.Page]; ok {
+			page = replacement
+			delete(replacements, page.Page)
+		}
+		merged.Pages = append(merged.Pages, page)
+	}
+	// pages missing from previous, e.g. because it was partial
+	for _, page := range replacements {
+		merged.Pages = append(merged.Pages, page)
+	}
+	slices.SortStableFunc(merged.Pages, func(a, b Page) int {
+		return a.Page - b.Page
+	})
+
+	return merged, err
+}
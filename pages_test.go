@@ -0,0 +1,146 @@
+package llamaparse
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestTargetPages(t *testing.T) {
+	tests := []struct {
+		pages []int
+		want  string
+	}{
+		{nil, ""},
+		{[]int{4}, "4"},
+		{[]int{0, 1, 2, 3, 7}, "0-3,7"},
+		{[]int{7, 2, 3, 0, 1}, "0-3,7"},
+		{[]int{1, 1, 2, 2, 5, 6, 9}, "1-2,5-6,9"},
+		{[]int{0, 2, 4}, "0,2,4"},
+	}
+
+	for _, test := range tests {
+		got := TargetPages(test.pages...)
+		if got != test.want {
+			t.Errorf("TargetPages(%v): got %q, want %q", test.pages, got, test.want)
+		}
+		if got != "" {
+			if err := validateTargetPages(got); err != nil {
+				t.Errorf("TargetPages(%v): %q doesn't validate: %v", test.pages, got, err)
+			}
+		}
+	}
+}
+
+func TestValidateTargetPages(t *testing.T) {
+	tests := []struct {
+		pages string
+		valid bool
+	}{
+		{"0", true},
+		{"0,1,2-5", true},
+		{"3-3", true},
+		{"10-2", false},
+		{"", false},
+		{"1,", false},
+		{",1", false},
+		{"1-", false},
+		{"1-2-3", false},
+		{"a", false},
+		{"-1", false},
+		{"1;2", false},
+		{"99999999999999999999-1", false},
+	}
+
+	for _, test := range tests {
+		err := validateTargetPages(test.pages)
+		if test.valid && err != nil {
+			t.Errorf("%q: %v", test.pages, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidTargetPages) {
+			t.Errorf("%q: got %v, want ErrInvalidTargetPages", test.pages, err)
+		}
+	}
+}
+
+func TestWithTargetPages(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, err := server.client(WithTargetPages(" 0, 2-4 ")).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.jobs["job-1"].form["target_pages"]; !slices.Equal(got, []string{"0,2-4"}) {
+		t.Errorf("got target_pages %q, want 0,2-4", got)
+	}
+
+	_, err = server.client(WithTargetPages("4-2")).Parse([]byte("text"), TEXT, WithFilename("a.txt"))
+	if !errors.Is(err, ErrInvalidTargetPages) {
+		t.Errorf("got %v, want ErrInvalidTargetPages", err)
+	}
+
+	if uploads := server.uploadCount(); uploads != 1 {
+		t.Errorf("got %d uploads, want 1", uploads)
+	}
+}
+
+func TestReparsePages(t *testing.T) {
+	previous := &ParseResult{Pages: []Page{{Page: 1, Text: "old 1"}, {Page: 2, Text: "old 2"}, {Page: 3, Text: "old 3"}, {Page: 4, Text: "old 4"}}}
+
+	tests := []struct {
+		name string
+		// result is the JSON result of the reparse
+		result string
+		want   []string
+	}{
+		{
+			name:   "numbered like the file",
+			result: `{"pages": [{"page": 2, "text": "new 2"}, {"page": 4, "text": "new 4"}]}`,
+			want:   []string{"old 1", "new 2", "old 3", "new 4"},
+		},
+		{
+			name:   "numbered from the first target",
+			result: `{"pages": [{"page": 1, "text": "new 2"}, {"page": 2, "text": "new 4"}]}`,
+			want:   []string{"old 1", "new 2", "old 3", "new 4"},
+		},
+		{
+			name:   "partial",
+			result: `{"pages": [{"page": 4, "text": "new 4"}]}`,
+			want:   []string{"old 1", "old 2", "old 3", "new 4"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeServer(t)
+			server.result = func(job *fakeJob, mode string) (int, string) {
+				return http.StatusOK, test.result
+			}
+
+			reparsed, err := server.client().ReparsePages([]byte("file"), []int{4, 2}, previous, WithFilename("a.pdf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for i, page := range reparsed.Pages {
+				got = append(got, page.Text)
+				if page.Page != i+1 {
+					t.Errorf("page %d is numbered %d", i+1, page.Page)
+				}
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if got := server.jobs["job-1"].form["target_pages"]; !slices.Equal(got, []string{"1,3"}) {
+				t.Errorf("got target_pages %q, want 1,3", got)
+			}
+		})
+	}
+
+	_, err := newFakeServer(t).client().ReparsePages([]byte("file"), []int{1}, nil)
+	if !errors.Is(err, ErrNoPreviousResult) {
+		t.Errorf("got %v for a nil previous, want ErrNoPreviousResult", err)
+	}
+}
@@ -0,0 +1,60 @@
+package llamaparse
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// PollStrategy decides how long to wait before each status check of a parsing job.
+type PollStrategy interface {
+	// Next returns the wait before the given attempt (starting at 0) and the last seen status, "" before the first check.
+	// Returning false stops polling.
+	Next(attempt int, status string) (time.Duration, bool)
+}
+
+// Progress is what is known about a job after one of its status checks.
+type Progress struct {
+	JobID string
+	// Status is the status of the job, PENDING while it is being parsed. It is empty when the check failed and is going to be retried.
+	Status string
+	// Elapsed is the time since the job was submitted.
+	Elapsed time.Duration
+}
+
+// FixedInterval waits the same amount of time before every status check.
+type FixedInterval struct {
+	Interval time.Duration
+}
+
+func (f FixedInterval) Next(attempt int, status string) (time.Duration, bool) {
+	return f.Interval, true
+}
+
+// ExponentialBackoff starts at Initial and multiplies the wait by Factor (2 if unset) after every check, up to Max (no cap if unset).
+// It is what a Client polls with unless WithPollStrategy says otherwise.
+type ExponentialBackoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+	// Jitter waits a random time between half of the wait and all of it, so jobs submitted together aren't checked in lockstep.
+	Jitter bool
+}
+
+func (e ExponentialBackoff) Next(attempt int, status string) (time.Duration, bool) {
+	factor := e.Factor
+	if factor <= 0 {
+		factor = 2
+	}
+
+	wait := time.Duration(float64(e.Initial) * math.Pow(factor, float64(attempt)))
+	if e.Max > 0 && (wait > e.Max || wait < 0) {
+		wait = e.Max
+	}
+
+	if e.Jitter && wait > 0 {
+		wait = wait/2 + rand.N(wait/2+1)
+	}
+
+	return wait, true
+}
@@ -0,0 +1,92 @@
+package llamaparse
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ParsePreset is the quality LlamaParse parses a file with. Unlike LlamaParseMode it doesn't change the output format, only the cost and fidelity.
+type ParsePreset string
+
+const (
+	// FAST skips OCR and image extraction, it is the cheapest but only works well on digital text.
+	FAST ParsePreset = "fast"
+	// BALANCED is the default of LlamaParse.
+	BALANCED ParsePreset = "balanced"
+	// PREMIUM uses the best available models, at a higher cost per page.
+	PREMIUM ParsePreset = "premium"
+)
+
+var PARSE_PRESETS = []ParsePreset{FAST, BALANCED, PREMIUM}
+
+// WithPreset sets the quality preset of the parse. Only one preset can be active at a time,
+// passing different ones in the same New call or the same parse call makes it fail with ErrConflictingPresets.
+// A preset passed to a call replaces the client's default.
+func WithPreset(preset ParsePreset) Option {
+	return func(c *Client) {
+		if !slices.Contains(PARSE_PRESETS, preset) {
+			c.err = fmt.Errorf("%w: %q", ErrUnknownPreset, preset)
+			return
+		}
+
+		if c.presetSet && c.preset != preset {
+			c.err = fmt.Errorf("%w: %s and %s", ErrConflictingPresets, c.preset, preset)
+			return
+		}
+
+		c.preset = preset
+		c.presetSet = true
+	}
+}
+
+// presetFields returns the form fields that select the preset.
+func presetFields(preset ParsePreset) []formField {
+	switch preset {
+	case FAST:
+		return []formField{{"fast_mode", "true"}}
+	case PREMIUM:
+		return []formField{{"premium_mode", "true"}}
+	default:
+		return nil
+	}
+}
+
+// AutoModeTriggers are what makes auto mode parse a page with the premium models. A page matching any of them is upgraded.
+type AutoModeTriggers struct {
+	TableInPage bool
+	ImageInPage bool
+	// TextInPage upgrades pages containing the text, RegexpInPage pages matching the regular expression.
+	TextInPage   string
+	RegexpInPage string
+}
+
+// WithAutoMode parses pages with the premium models only when they match triggers and the rest with the client's preset,
+// which is the cheapest way to get tables and images of mixed documents right.
+func WithAutoMode(triggers AutoModeTriggers) Option {
+	return func(c *Client) {
+		c.autoMode = &triggers
+	}
+}
+
+// autoModeFields returns the form fields that enable auto mode with triggers, or none without it.
+func autoModeFields(triggers *AutoModeTriggers) []formField {
+	if triggers == nil {
+		return nil
+	}
+
+	fields := []formField{{"auto_mode", "true"}}
+	if triggers.TableInPage {
+		fields = append(fields, formField{"auto_mode_trigger_on_table_in_page", "true"})
+	}
+	if triggers.ImageInPage {
+		fields = append(fields, formField{"auto_mode_trigger_on_image_in_page", "true"})
+	}
+	if triggers.TextInPage != "" {
+		fields = append(fields, formField{"auto_mode_trigger_on_text_in_page", triggers.TextInPage})
+	}
+	if triggers.RegexpInPage != "" {
+		fields = append(fields, formField{"auto_mode_trigger_on_regexp_in_page", triggers.RegexpInPage})
+	}
+
+	return fields
+}
@@ -0,0 +1,64 @@
+package llamaparse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+)
+
+// recorder writes the requests of a client and their responses to w, one at a time.
+type recorder struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// WithRecorder makes the client write every request it sends and the response to it to w, headers and bodies, e.g. for a bug report.
+// The Authorization header is redacted. Bodies are read whole to be written, so streamed uploads and large results are held in memory.
+// A nil w disables recording.
+func WithRecorder(w io.Writer) Option {
+	return func(c *Client) {
+		if w == nil {
+			c.recorder = nil
+			return
+		}
+
+		c.recorder = &recorder{w: w}
+	}
+}
+
+// recordRequest writes req to the recorder, leaving its body readable.
+func (r *recorder) recordRequest(req *http.Request) error {
+	redacted := req.Clone(req.Context())
+	if redacted.Header.Get("Authorization") != "" {
+		redacted.Header.Set("Authorization", "[REDACTED]")
+	}
+
+	dump, err := httputil.DumpRequestOut(redacted, true)
+	if err != nil {
+		return err
+	}
+	// the dump read the body, the clone got a copy of it
+	req.Body = redacted.Body
+
+	return r.write(dump)
+}
+
+// recordResponse writes resp to the recorder, leaving its body readable.
+func (r *recorder) recordResponse(resp *http.Response) error {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return err
+	}
+
+	return r.write(dump)
+}
+
+func (r *recorder) write(dump []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, err := fmt.Fprintf(r.w, "%s\n\n", dump)
+	return err
+}
@@ -0,0 +1,62 @@
+package llamaparse
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithRecorder(t *testing.T) {
+	server := newFakeServer(t)
+	var recorded bytes.Buffer
+
+	file := strings.Repeat("recorded upload ", 1000)
+	result, err := server.client(WithRecorder(&recorded)).Parse([]byte(file), TEXT, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the body was read for the recording and still sent whole
+	if got := server.jobs["job-1"].file; got != file {
+		t.Errorf("the server got %d bytes, want the %d of the file", len(got), len(file))
+	}
+	if result != file {
+		t.Errorf("got %d bytes back, want %d", len(result), len(file))
+	}
+
+	dump := recorded.String()
+	if strings.Contains(dump, "test-key") {
+		t.Error("the API key was recorded")
+	}
+	if !strings.Contains(dump, "Authorization: [REDACTED]") {
+		t.Error("the Authorization header isn't in the recording")
+	}
+	for _, want := range []string{"POST /api/parsing/upload", "GET /api/parsing/job/job-1 ", "GET /api/parsing/job/job-1/result/text", "HTTP/1.1 200 OK", file} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("the recording doesn't have %.40q", want)
+		}
+	}
+}
+
+func TestRecordRequestKeepsHeader(t *testing.T) {
+	req, err := http.NewRequest("GET", "https://example.com/job", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+
+	var recorded bytes.Buffer
+	err = (&recorder{w: &recorded}).recordRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(recorded.String(), "secret") {
+		t.Error("the API key was recorded")
+	}
+	// only the copy is redacted, the request is still sent with the key
+	if got := req.Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("got Authorization %q on the request", got)
+	}
+}
@@ -0,0 +1,237 @@
+package llamaparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ResultDecoder extracts the content of a mode from the raw result document.
+type ResultDecoder func(result []byte) (string, error)
+
+var (
+	resultDecodersMutex sync.RWMutex
+	resultDecoders      = map[LlamaParseMode]ResultDecoder{
+		MARKDOWN: decodeStringField(MARKDOWN),
+		TEXT:     decodeStringField(TEXT),
+		JSON:     decodeJSONDocument,
+	}
+)
+
+// decodeJSONDocument returns the JSON result as is, it is a document, not a string. A document without pages isn't ready yet.
+func decodeJSONDocument(result []byte) (string, error) {
+	var resultResponse map[string]json.RawMessage
+	err := json.Unmarshal(result, &resultResponse)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := resultResponse["pages"]; !ok {
+		return "", ErrParsingFailed
+	}
+
+	return string(result), nil
+}
+
+// decodeStringField returns a decoder for modes whose content is a string under the mode's name.
+func decodeStringField(mode LlamaParseMode) ResultDecoder {
+	return func(result []byte) (string, error) {
+		var resultResponse map[string]json.RawMessage
+		err := json.Unmarshal(result, &resultResponse)
+		if err != nil {
+			return "", err
+		}
+
+		var content string
+		err = json.Unmarshal(resultResponse[string(mode)], &content)
+		if err != nil {
+			return "", ErrParsingFailed
+		}
+
+		return content, nil
+	}
+}
+
+// RegisterResultDecoder sets how the content of a mode is extracted from its result, replacing any decoder already registered for it.
+// Modes without a decoder are expected to have a string under the mode's name, like markdown and text.
+func RegisterResultDecoder(mode LlamaParseMode, decoder ResultDecoder) {
+	resultDecodersMutex.Lock()
+	defer resultDecodersMutex.Unlock()
+
+	resultDecoders[mode] = decoder
+}
+
+func decodeResult(result []byte, mode LlamaParseMode) (string, error) {
+	resultDecodersMutex.RLock()
+	decoder, ok := resultDecoders[mode]
+	resultDecodersMutex.RUnlock()
+
+	if !ok {
+		decoder = decodeStringField(mode)
+	}
+
+	return decoder(result)
+}
+
+// JobMetadata is what LlamaParse reports about a finished job alongside every result.
+type JobMetadata struct {
+	CreditsUsed               float64 `json:"credits_used"`
+	CreditsMax                int64   `json:"credits_max"`
+	JobCreditsUsage           int64   `json:"job_credits_usage"`
+	JobPages                  int     `json:"job_pages"`
+	JobAutoModeTriggeredPages int     `json:"job_auto_mode_triggered_pages"`
+	JobIsCacheHit             bool    `json:"job_is_cache_hit"`
+}
+
+// ParseResult is the JSON mode result of a job. Fields LlamaParse adds later are ignored.
+type ParseResult struct {
+	Pages       []Page      `json:"pages"`
+	JobMetadata JobMetadata `json:"job_metadata"`
+}
+
+// Page is a single page of a JSON mode result.
+type Page struct {
+	Page     int     `json:"page"`
+	Text     string  `json:"text"`
+	Markdown string  `json:"md"`
+	Images   []Image `json:"images"`
+	Items    []Item  `json:"items"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+}
+
+// Item is a layout element of a page, e.g. a heading, text block or table.
+type Item struct {
+	Type     string `json:"type"`
+	Level    int    `json:"lvl,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Markdown string `json:"md,omitempty"`
+	// Rows and CSV are only set for tables.
+	Rows [][]string `json:"rows,omitempty"`
+	CSV  string     `json:"csv,omitempty"`
+	BBox *BBox      `json:"bBox,omitempty"`
+}
+
+// Image is an image extracted from a page.
+type Image struct {
+	Name string `json:"name"`
+	// Type is SCREENSHOT_IMAGE_TYPE for the rendered page WithTakeScreenshot adds, empty for images found on the page.
+	Type   string  `json:"type,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// BBox is the position of an item on its page.
+type BBox struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// DecodeResult decodes a JSON mode result, as returned by ParseBytes with the JSON mode.
+func DecodeResult(result []byte) (*ParseResult, error) {
+	var parseResult ParseResult
+	err := json.Unmarshal(result, &parseResult)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parseResult, nil
+}
+
+// ResultRecord is a line of a JSONL file written by AppendResult.
+type ResultRecord struct {
+	JobID       string      `json:"job_id"`
+	Content     string      `json:"content"`
+	JobMetadata JobMetadata `json:"job_metadata"`
+}
+
+/*
+AppendResult writes a parsed file as a single JSONL line, for files that are read by streaming ingestion.
+
+If w has a Flush method, like a bufio.Writer, it is called so the line is complete once AppendResult returns.
+
+Args:
+
+	w: Where to write the record, typically a file opened with os.O_APPEND.
+	jobID: The ID of the job the result came from.
+	result: The parsed file.
+	meta: The job metadata.
+*/
+func AppendResult(w io.Writer, jobID string, result string, meta JobMetadata) error {
+	encoder := json.NewEncoder(w)
+	// markdown is full of <, > and &, keep it readable
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(ResultRecord{
+		JobID:       jobID,
+		Content:     result,
+		JobMetadata: meta,
+	})
+	if err != nil {
+		return err
+	}
+
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+
+	return nil
+}
+
+// SplitPages encodes every page of a JSON mode result as its own JSON document.
+func SplitPages(result *ParseResult) ([][]byte, error) {
+	pages := make([][]byte, 0, len(result.Pages))
+	for _, page := range result.Pages {
+		encoded, err := json.MarshalIndent(page, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, encoded)
+	}
+
+	return pages, nil
+}
+
+/*
+WritePages writes every page of a JSON mode result to its own file.
+
+Args:
+
+	result: The result to split, from DecodeResult.
+	dir: The directory to write to. It is created if it doesn't exist.
+	name: The prefix of the files, pages are written to <name>-p<page>.json.
+
+Returns:
+
+	The paths of the written files, in page order.
+*/
+func WritePages(result *ParseResult, dir string, name string) ([]string, error) {
+	pages, err := SplitPages(result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(pages))
+	for i, page := range pages {
+		path := filepath.Join(dir, fmt.Sprintf("%s-p%d.json", name, result.Pages[i].Page))
+		err = os.WriteFile(path, page, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,72 @@
+package llamaparse
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeResult(t *testing.T) {
+	data, err := os.ReadFile("testdata/result.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := DecodeResult(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ParseResult{
+		Pages: []Page{
+			{
+				Page:     1,
+				Text:     "Quarterly report\nRevenue grew.",
+				Markdown: "# Quarterly report\n\nRevenue grew.",
+				Images: []Image{
+					{Name: "img_p0_1.png", X: 10, Y: 20, Width: 100, Height: 50},
+					{Name: "page_1.jpg", Type: SCREENSHOT_IMAGE_TYPE, Width: 612, Height: 792},
+				},
+				Items: []Item{
+					{Type: "heading", Level: 1, Value: "Quarterly report", Markdown: "# Quarterly report", BBox: &BBox{X: 72, Y: 72, W: 200, H: 24}},
+					{Type: "text", Value: "Revenue grew.", Markdown: "Revenue grew."},
+					{
+						Type:     "table",
+						Rows:     [][]string{{"Quarter", "Revenue"}, {"Q1", "10"}},
+						CSV:      "\"Quarter\",\"Revenue\"\n\"Q1\",\"10\"",
+						Markdown: "|Quarter|Revenue|\n|---|---|\n|Q1|10|",
+					},
+				},
+				Width:  612,
+				Height: 792,
+			},
+			{
+				Page:   2,
+				Images: []Image{},
+				Items:  []Item{},
+				Width:  612,
+				Height: 792,
+			},
+		},
+		JobMetadata: JobMetadata{
+			CreditsUsed:               2.5,
+			CreditsMax:                1000,
+			JobCreditsUsage:           2,
+			JobPages:                  2,
+			JobAutoModeTriggeredPages: 1,
+		},
+	}
+
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+}
+
+func TestDecodeResultInvalid(t *testing.T) {
+	for _, data := range []string{"", "not json", `{"pages": "none"}`} {
+		_, err := DecodeResult([]byte(data))
+		if err == nil {
+			t.Errorf("%q: no error", data)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package llamaparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJob is a job submitted to a fakeServer.
+type fakeJob struct {
+	file string
+	// form is the form the file was uploaded with, without the file.
+	form   map[string][]string
+	checks int
+}
+
+// fakeServer is a LlamaParse API in memory. Every job succeeds and its markdown and text are the uploaded file, unless status or result say otherwise.
+type fakeServer struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	uploads int
+	jobs    map[string]*fakeJob
+
+	// status returns the status of a job after its checks-th check, counted from 1.
+	status func(job *fakeJob) string
+	// result returns the status code and body of a result request, a zero status code answers with the default result.
+	result func(job *fakeJob, mode string) (int, string)
+	// images are the images every job extracted, by name.
+	images map[string]string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	server := &fakeServer{jobs: map[string]*fakeJob{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/parsing/upload", server.handleUpload)
+	mux.HandleFunc("GET /api/parsing/job/{id}", server.handleStatus)
+	mux.HandleFunc("GET /api/parsing/job/{id}/result/{mode}", server.handleResult)
+	mux.HandleFunc("GET /api/parsing/job/{id}/result/image/{name}", server.handleImage)
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// client returns a client talking to the server that polls without waiting.
+func (s *fakeServer) client(opts ...Option) *Client {
+	opts = append([]Option{
+		WithBaseURL(s.URL),
+		WithPollStrategy(FixedInterval{Interval: time.Millisecond}),
+	}, opts...)
+
+	return New("test-key", opts...)
+}
+
+func (s *fakeServer) handleUpload(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.uploads++
+	id := fmt.Sprintf("job-%d", s.uploads)
+	s.jobs[id] = &fakeJob{file: string(content), form: r.MultipartForm.Value}
+	s.mutex.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]string{"id": id})
+}
+
+func (s *fakeServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	if !ok {
+		s.mutex.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	job.checks++
+	status := "SUCCESS"
+	statusFunc := s.status
+	s.mutex.Unlock()
+
+	if statusFunc != nil {
+		status = statusFunc(job)
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+func (s *fakeServer) handleResult(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	resultFunc := s.result
+	s.mutex.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	mode := r.PathValue("mode")
+	if resultFunc != nil {
+		statusCode, body := resultFunc(job, mode)
+		if statusCode != 0 {
+			w.WriteHeader(statusCode)
+			io.WriteString(w, body)
+			return
+		}
+	}
+
+	if mode == string(JSON) {
+		pages := []Page{}
+		for i, page := range strings.Split(job.file, "\f") {
+			pages = append(pages, Page{Page: i + 1, Markdown: page, Text: page})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"pages": pages, "job_metadata": map[string]any{"job_pages": len(pages)}})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{mode: job.file, "job_metadata": map[string]any{"job_pages": 1}})
+}
+
+func (s *fakeServer) handleImage(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	_, ok := s.jobs[r.PathValue("id")]
+	image, found := s.images[r.PathValue("name")]
+	s.mutex.Unlock()
+	if !ok || !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	io.WriteString(w, image)
+}
+
+func (s *fakeServer) uploadCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.uploads
+}
@@ -0,0 +1,119 @@
+package llamaparse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// How many bytes from the start of a file are looked at to tell its type, the same as http.DetectContentType.
+const SNIFF_SIZE_BYTES = 512
+
+var (
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	zipMagic = []byte("PK\x03\x04")
+
+	// the legacy office formats are OLE containers and the current ones zip archives, a file renamed from one to the other is corrected
+	oleExtensions = map[string]string{".docx": ".doc", ".xlsx": ".xls", ".pptx": ".ppt"}
+	zipExtensions = map[string]string{".doc": ".docx", ".xls": ".xlsx", ".ppt": ".pptx"}
+)
+
+// sniffExtension returns the extension the contents starting with head have, when it disagrees with the one of fileName.
+// An empty result means the extension matches or the contents don't tell.
+func sniffExtension(head []byte, fileName string) string {
+	extension := strings.ToLower(filepath.Ext(fileName))
+
+	var sniffed string
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		sniffed = ".pdf"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		sniffed = ".png"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		if extension == ".jpeg" {
+			return ""
+		}
+		sniffed = ".jpg"
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		sniffed = ".gif"
+	case len(head) >= 12 && bytes.HasPrefix(head, []byte("RIFF")) && string(head[8:12]) == "WEBP":
+		sniffed = ".webp"
+	case bytes.HasPrefix(head, oleMagic):
+		sniffed = oleExtensions[extension]
+	case bytes.HasPrefix(head, zipMagic):
+		sniffed = zipExtensions[extension]
+	case extension == ".csv":
+		// only the first line is looked at, it is the header of most exports
+		line, _, _ := bytes.Cut(head, []byte("\n"))
+		if bytes.Contains(line, []byte("\t")) && !bytes.Contains(line, []byte(",")) {
+			sniffed = ".tsv"
+		}
+	}
+
+	if sniffed == extension {
+		return ""
+	}
+
+	return sniffed
+}
+
+// correctFileType renames fileName and replaces mimeType when the start of file shows they are wrong. file is read through the returned reader.
+// A file that can be rewound is returned as is, so its upload can still be retried.
+func correctFileType(file io.Reader, fileName string, mimeType string) (io.Reader, string, string) {
+	var head []byte
+	buffered := file
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		head = sniffSeeker(seeker)
+	} else {
+		reader := bufio.NewReaderSize(file, SNIFF_SIZE_BYTES)
+		// a shorter file returns what it has along with the error
+		head, _ = reader.Peek(SNIFF_SIZE_BYTES)
+		buffered = reader
+	}
+
+	extension := sniffExtension(head, fileName)
+	if extension == "" {
+		return buffered, fileName, mimeType
+	}
+
+	if fileName == "" {
+		fileName = "uploadfile"
+	}
+	fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + extension
+
+	for candidate, candidateExtension := range MIME_TYPE_EXTENSIONS {
+		if candidateExtension == extension {
+			return buffered, fileName, candidate
+		}
+	}
+
+	return buffered, fileName, mimeTypeFromName(fileName)
+}
+
+// sniffSeeker reads the start of file and seeks back to where it was. Nothing is sniffed from a file that can't seek back.
+func sniffSeeker(file io.ReadSeeker) []byte {
+	start, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+
+	head := make([]byte, SNIFF_SIZE_BYTES)
+	n, _ := io.ReadFull(file, head)
+
+	_, err = file.Seek(start, io.SeekStart)
+	if err != nil {
+		return nil
+	}
+
+	return head[:n]
+}
+
+// WithMIMEAutoCorrect makes the client look at the first SNIFF_SIZE_BYTES of each file and, when they show its extension is wrong,
+// upload it under the right extension and MIME type, e.g. a .docx that is really a .doc or a .csv separated by tabs.
+func WithMIMEAutoCorrect(autoCorrect bool) Option {
+	return func(c *Client) {
+		c.mimeAutoCorrect = autoCorrect
+	}
+}
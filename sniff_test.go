@@ -0,0 +1,90 @@
+package llamaparse
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+var (
+	pdfHead  = "%PDF-1.7\n"
+	pngHead  = "\x89PNG\r\n\x1a\n"
+	jpegHead = "\xFF\xD8\xFF\xE0"
+	oleHead  = string(oleMagic)
+	zipHead  = string(zipMagic)
+)
+
+func TestSniffExtension(t *testing.T) {
+	tests := []struct {
+		head     string
+		fileName string
+		want     string
+	}{
+		{pdfHead, "a.pdf", ""},
+		{pdfHead, "a.PDF", ""},
+		{pdfHead, "a.txt", ".pdf"},
+		{pdfHead, "", ".pdf"},
+		{pngHead, "a.jpg", ".png"},
+		{jpegHead, "a.jpg", ""},
+		{jpegHead, "a.jpeg", ""},
+		{jpegHead, "a.png", ".jpg"},
+		{"GIF89a", "a.png", ".gif"},
+		{"RIFF\x00\x00\x00\x00WEBP", "a.png", ".webp"},
+		{oleHead, "a.docx", ".doc"},
+		{oleHead, "a.doc", ""},
+		{oleHead, "a.xlsx", ".xls"},
+		{zipHead, "a.doc", ".docx"},
+		{zipHead, "a.pptx", ""},
+		// a zip that isn't named as an office file could be anything
+		{zipHead, "a.zip", ""},
+		{"a\tb\tc\n1,5\t2\t3\n", "a.csv", ".tsv"},
+		{"a,b,c\n1\t2\t3\n", "a.csv", ""},
+		{"a\tb\tc\n", "a.txt", ""},
+		{"plain text", "a.pdf", ""},
+		{"", "a.pdf", ""},
+	}
+
+	for _, test := range tests {
+		got := sniffExtension([]byte(test.head), test.fileName)
+		if got != test.want {
+			t.Errorf("sniffExtension(%q, %q): got %q, want %q", test.head, test.fileName, got, test.want)
+		}
+	}
+}
+
+func TestCorrectFileType(t *testing.T) {
+	tests := []struct {
+		file         string
+		fileName     string
+		mimeType     string
+		wantFileName string
+		wantMimeType string
+	}{
+		{pdfHead, "report.pdf", "application/pdf", "report.pdf", "application/pdf"},
+		{pdfHead, "report.txt", "text/plain", "report.pdf", "application/pdf"},
+		{pdfHead, "", "", "uploadfile.pdf", "application/pdf"},
+		{pngHead, "scan.jpg", "image/jpeg", "scan.png", "image/png"},
+		{oleHead, "letter.docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "letter.doc", "application/msword"},
+		{zipHead, "sheet.xls", "application/vnd.ms-excel", "sheet.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"a\tb\n1\t2\n", "export.csv", "text/csv", "export.tsv", "text/tab-separated-values"},
+		{"plain text", "notes.txt", "text/plain", "notes.txt", "text/plain"},
+	}
+
+	for _, test := range tests {
+		// both a file that can be rewound and one that can't
+		for _, file := range []io.Reader{strings.NewReader(test.file), struct{ io.Reader }{strings.NewReader(test.file)}} {
+			reader, fileName, mimeType := correctFileType(file, test.fileName, test.mimeType)
+			if fileName != test.wantFileName || mimeType != test.wantMimeType {
+				t.Errorf("correctFileType(%q, %q, %q): got %q and %q, want %q and %q", test.file, test.fileName, test.mimeType, fileName, mimeType, test.wantFileName, test.wantMimeType)
+			}
+
+			contents, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(contents) != test.file {
+				t.Errorf("correctFileType(%q, %q, %q): the returned reader gave %q", test.file, test.fileName, test.mimeType, contents)
+			}
+		}
+	}
+}
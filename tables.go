@@ -0,0 +1,138 @@
+package llamaparse
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The line LlamaParse puts between the pages of a markdown result unless told otherwise.
+const DEFAULT_PAGE_SEPARATOR = "---"
+
+var tableSeparatorRow = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+
+type markdownTable struct {
+	start, end int // line range, end exclusive
+	columns    int
+	header     string
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+func tableColumns(row string) int {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	row = strings.ReplaceAll(row, `\|`, "")
+
+	return strings.Count(row, "|") + 1
+}
+
+func findTables(lines []string) []markdownTable {
+	var tables []markdownTable
+
+	for i := 0; i < len(lines); i++ {
+		if !isTableRow(lines[i]) || i+1 >= len(lines) || !tableSeparatorRow.MatchString(strings.TrimSpace(lines[i+1])) {
+			continue
+		}
+
+		end := i + 2
+		for end < len(lines) && isTableRow(lines[end]) {
+			end++
+		}
+
+		tables = append(tables, markdownTable{
+			start:   i,
+			end:     end,
+			columns: tableColumns(lines[i]),
+			header:  strings.TrimSpace(lines[i]),
+		})
+		i = end - 1
+	}
+
+	return tables
+}
+
+// onlyPageBreak reports whether the lines between two tables are nothing but blank lines and page separators.
+func onlyPageBreak(lines []string, pageSeparator string) bool {
+	sawSeparator := false
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+		case pageSeparator:
+			sawSeparator = true
+		default:
+			return false
+		}
+	}
+
+	return sawSeparator
+}
+
+/*
+MergeTables joins markdown tables that were split by a page break back into a single table.
+
+Two tables are merged when the only thing between them is the page separator (DEFAULT_PAGE_SEPARATOR unless LlamaParse was told otherwise) and they have the same number of columns.
+The second table's header row is dropped if it repeats the first one's, otherwise it is kept as a regular row, as it is usually the first row of the continued table.
+
+Args:
+
+	markdown: The markdown result.
+	pageSeparator: The line LlamaParse put between pages.
+
+Returns:
+
+	The markdown with the table fragments merged.
+*/
+func MergeTables(markdown string, pageSeparator string) string {
+	lines := strings.Split(markdown, "\n")
+	tables := findTables(lines)
+	if len(tables) < 2 {
+		return markdown
+	}
+
+	pageSeparator = strings.TrimSpace(pageSeparator)
+
+	var merged []string
+	last := 0
+	previous := tables[0]
+
+	for _, table := range tables[1:] {
+		if table.columns != previous.columns || !onlyPageBreak(lines[previous.end:table.start], pageSeparator) {
+			previous = table
+			continue
+		}
+
+		merged = append(merged, lines[last:previous.end]...)
+		if table.header != previous.header {
+			merged = append(merged, lines[table.start])
+		}
+
+		// the separator row is dropped, the rest continues the previous table
+		last = table.start + 2
+		previous.end = table.end
+	}
+
+	merged = append(merged, lines[last:]...)
+
+	return strings.Join(merged, "\n")
+}
+
+// WithMergeTables makes markdown results come with the tables split by a page break merged back together, see MergeTables.
+func WithMergeTables(merge bool) Option {
+	return func(c *Client) {
+		c.mergeTables = merge
+	}
+}
+
+// WithTablesAsHTML makes LlamaParse write the tables of markdown results as HTML tables rather than markdown ones,
+// which keeps merged cells and multi-line cells that markdown can't express. MergeTables only joins markdown tables.
+// LlamaParse has no CSV output mode, but the tables of JSON results come with their rows and CSV in Item.Rows and Item.CSV either way.
+func WithTablesAsHTML(asHTML bool) Option {
+	return func(c *Client) {
+		c.tablesAsHTML = asHTML
+	}
+}
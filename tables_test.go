@@ -0,0 +1,145 @@
+package llamaparse
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestWithTablesAsHTML(t *testing.T) {
+	server := newFakeServer(t)
+
+	// a result recorded with output_tables_as_HTML, which escapes the <, > and & of the tables in JSON
+	server.result = func(job *fakeJob, mode string) (int, string) {
+		switch mode {
+		case string(MARKDOWN):
+			return http.StatusOK, `{"markdown":"# Totals\n\n\u003ctable\u003e\u003ctr\u003e\u003cth\u003eItem\u003c/th\u003e\u003cth\u003ePrice \u0026amp; tax\u003c/th\u003e\u003c/tr\u003e\u003ctr\u003e\u003ctd\u003e\"a\" \u0026lt; b\u003c/td\u003e\u003ctd\u003e1\u003c/td\u003e\u003c/tr\u003e\u003c/table\u003e\n","job_metadata":{"job_pages":1}}`
+		case string(JSON):
+			return http.StatusOK, `{"pages":[{"page":1,"md":"\u003ctable\u003e\u003ctr\u003e\u003cth\u003eItem\u003c/th\u003e\u003c/tr\u003e\u003c/table\u003e","items":[{"type":"table","md":"\u003ctable\u003e\u003ctr\u003e\u003cth\u003eItem\u003c/th\u003e\u003c/tr\u003e\u003ctr\u003e\u003ctd\u003ea, b\u003c/td\u003e\u003c/tr\u003e\u003c/table\u003e","rows":[["Item"],["a, b"]],"csv":"\"Item\"\n\"a, b\""}]}],"job_metadata":{"job_pages":1}}`
+		}
+		return 0, ""
+	}
+
+	client := server.client(WithTablesAsHTML(true), WithFilename("table.pdf"))
+
+	markdown, err := client.Parse([]byte("table"), MARKDOWN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Totals\n\n<table><tr><th>Item</th><th>Price &amp; tax</th></tr><tr><td>\"a\" &lt; b</td><td>1</td></tr></table>\n"
+	if markdown != want {
+		t.Errorf("got %q, want %q", markdown, want)
+	}
+
+	// the tables of JSON results also come as rows and CSV
+	pages, err := client.ParseJSON([]byte("table"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || len(pages[0].Items) != 1 {
+		t.Fatalf("got %+v, want one page with one table", pages)
+	}
+	table := pages[0].Items[0]
+	if table.Markdown != "<table><tr><th>Item</th></tr><tr><td>a, b</td></tr></table>" {
+		t.Errorf("got table %q", table.Markdown)
+	}
+	if !reflect.DeepEqual(table.Rows, [][]string{{"Item"}, {"a, b"}}) || table.CSV != "\"Item\"\n\"a, b\"" {
+		t.Errorf("got rows %q and CSV %q", table.Rows, table.CSV)
+	}
+
+	for id, job := range server.jobs {
+		if !slices.Equal(job.form["output_tables_as_HTML"], []string{"true"}) {
+			t.Errorf("%s: got output_tables_as_HTML %q, want true", id, job.form["output_tables_as_HTML"])
+		}
+	}
+}
+
+func TestWithTablesAsHTMLDisabled(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, err := server.client().Parse([]byte("text"), MARKDOWN, WithFilename("a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id, job := range server.jobs {
+		if _, ok := job.form["output_tables_as_HTML"]; ok {
+			t.Errorf("%s: output_tables_as_HTML sent without WithTablesAsHTML", id)
+		}
+	}
+}
+
+func TestMergeTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "repeated header",
+			markdown: "| a | b |\n|---|---|\n| 1 | 2 |\n\n---\n\n| a | b |\n|---|---|\n| 3 | 4 |",
+			want:     "| a | b |\n|---|---|\n| 1 | 2 |\n| 3 | 4 |",
+		},
+		{
+			name:     "continued without header",
+			markdown: "| a | b |\n|---|---|\n| 1 | 2 |\n---\n| 3 | 4 |\n|---|---|\n| 5 | 6 |",
+			want:     "| a | b |\n|---|---|\n| 1 | 2 |\n| 3 | 4 |\n| 5 | 6 |",
+		},
+		{
+			name:     "three pages",
+			markdown: "| a |\n|---|\n| 1 |\n---\n| a |\n|---|\n| 2 |\n---\n| a |\n|---|\n| 3 |\n\nafter",
+			want:     "| a |\n|---|\n| 1 |\n| 2 |\n| 3 |\n\nafter",
+		},
+		{
+			name:     "different columns",
+			markdown: "| a | b |\n|---|---|\n| 1 | 2 |\n---\n| c |\n|---|\n| 3 |",
+			want:     "| a | b |\n|---|---|\n| 1 | 2 |\n---\n| c |\n|---|\n| 3 |",
+		},
+		{
+			name:     "text between",
+			markdown: "| a |\n|---|\n| 1 |\n---\nmore text\n| a |\n|---|\n| 2 |",
+			want:     "| a |\n|---|\n| 1 |\n---\nmore text\n| a |\n|---|\n| 2 |",
+		},
+		{
+			name:     "no page break",
+			markdown: "| a |\n|---|\n| 1 |\n\n| a |\n|---|\n| 2 |",
+			want:     "| a |\n|---|\n| 1 |\n\n| a |\n|---|\n| 2 |",
+		},
+		{
+			name:     "one table",
+			markdown: "# Title\n\n| a |\n|---|\n| 1 |",
+			want:     "# Title\n\n| a |\n|---|\n| 1 |",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MergeTables(test.markdown, DEFAULT_PAGE_SEPARATOR)
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithMergeTables(t *testing.T) {
+	server := newFakeServer(t)
+	markdown := "| a |\n|---|\n| 1 |\n---\n| a |\n|---|\n| 2 |"
+
+	merged, err := server.client(WithMergeTables(true)).Parse([]byte(markdown), MARKDOWN, WithFilename("table.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "| a |\n|---|\n| 1 |\n| 2 |"; merged != want {
+		t.Errorf("got %q, want %q", merged, want)
+	}
+
+	unmerged, err := server.client().Parse([]byte(markdown), MARKDOWN, WithFilename("table.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unmerged != markdown {
+		t.Errorf("got %q without WithMergeTables, want it unchanged", unmerged)
+	}
+}
@@ -0,0 +1,58 @@
+package llamaparse
+
+import (
+	"io"
+	"net/http"
+)
+
+// progressReader reports how many bytes of a request body of total bytes were read from it.
+type progressReader struct {
+	io.ReadCloser
+	sent       int64
+	total      int64
+	onProgress func(sent int64, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.onProgress(r.sent, r.total)
+	}
+
+	return n, err
+}
+
+// trackUploadProgress makes the body of req, and the bodies GetBody returns for its retries, report their progress to onProgress.
+func trackUploadProgress(req *http.Request, onProgress func(sent int64, total int64)) {
+	if req.Body == nil {
+		return
+	}
+
+	total := req.ContentLength
+	if total == 0 {
+		// an unknown length, NewRequest only knows it for in-memory bodies
+		total = -1
+	}
+
+	req.Body = &progressReader{ReadCloser: req.Body, total: total, onProgress: onProgress}
+
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+
+			return &progressReader{ReadCloser: body, total: total, onProgress: onProgress}, nil
+		}
+	}
+}
+
+// WithUploadProgress makes the client call onProgress as the file is uploaded, with the bytes of the upload sent so far and its total size.
+// The total is -1 for streamed files, whose size isn't known before they are read. A retried upload starts over from 0.
+func WithUploadProgress(onProgress func(sent int64, total int64)) Option {
+	return func(c *Client) {
+		c.onUploadProgress = onProgress
+	}
+}
@@ -0,0 +1,132 @@
+package llamaparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+)
+
+// ZipEntryResult is the parsed content of a single file from a ZIP archive.
+type ZipEntryResult struct {
+	Name    string
+	Content string
+}
+
+/*
+ParseZip parses every file of a ZIP archive using the LlamaParse API.
+
+LlamaParse doesn't accept archives, so the entries are extracted and parsed one by one. Directories are skipped, and so are empty files unless WithAllowEmptyFile is set.
+An entry larger than DEFAULT_MAX_ZIP_ENTRY_SIZE_BYTES, or than the size the archive declares for it, fails with ErrZipEntryTooLarge.
+
+Args:
+
+	file: The ZIP archive.
+	The rest is the same as Parse.
+
+Returns:
+
+	The parsed entries in the order they appear in the archive.
+	Entries that were only partly parsed are kept, the returned error then joins their *JobError wrapping ErrPartialSuccess.
+*/
+func ParseZip(file []byte, mode LlamaParseMode, apiKeyOptional *string, languageOptional *string, timeoutSecondsOptional *int, checkIntervalSecondsOptional *int) ([]ZipEntryResult, error) {
+	return clientFromOptional(apiKeyOptional, timeoutSecondsOptional, checkIntervalSecondsOptional).parseZip(context.Background(), file, mode, languageOptional)
+}
+
+/*
+ParseZip parses every file of a ZIP archive, each uploaded under the name of its entry.
+
+Args:
+
+	file: The ZIP archive.
+	mode: The output format (markdown, text, json).
+	opts: Options for this call only. WithFilename has no effect, the entries keep their own names. WithMaxZipEntrySize changes the size limit of the entries.
+
+Returns:
+
+	The parsed entries in the order they appear in the archive.
+	Entries that were only partly parsed are kept, the returned error then joins their *JobError wrapping ErrPartialSuccess.
+*/
+func (c *Client) ParseZip(file []byte, mode LlamaParseMode, opts ...Option) ([]ZipEntryResult, error) {
+	return c.with(opts).parseZip(context.Background(), file, mode, nil)
+}
+
+func (c *Client) parseZip(ctx context.Context, file []byte, mode LlamaParseMode, language *string) ([]ZipEntryResult, error) {
+	if len(file) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(file), int64(len(file)))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ZipEntryResult
+	var partialErrs []error
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || (entry.UncompressedSize64 == 0 && !c.allowEmptyFile) {
+			continue
+		}
+
+		content, err := readZipEntry(entry, c.maxZipEntrySize)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+
+		jobID, resultBytes, err := c.parseFile(ctx, content, path.Base(entry.Name), "", mode, language)
+		result, err := c.decodeJobResult(ctx, jobID, resultBytes, mode, err)
+		if err != nil && !errors.Is(err, ErrPartialSuccess) {
+			return nil, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		if err != nil {
+			partialErrs = append(partialErrs, fmt.Errorf("%s: %w", entry.Name, err))
+		}
+
+		results = append(results, ZipEntryResult{
+			Name:    entry.Name,
+			Content: result,
+		})
+	}
+
+	return results, errors.Join(partialErrs...)
+}
+
+// readZipEntry extracts entry, failing with ErrZipEntryTooLarge once it is larger than maxBytes or its declared size. A maxBytes of 0 disables the limit.
+func readZipEntry(entry *zip.File, maxBytes int64) ([]byte, error) {
+	limit := entry.UncompressedSize64
+	if maxBytes > 0 && limit > uint64(maxBytes) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrZipEntryTooLarge, limit)
+	}
+
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// the declared size can be a lie, reading one byte past it tells
+	content, err := io.ReadAll(io.LimitReader(reader, int64(min(limit, math.MaxInt64-1))+1))
+	if errors.Is(err, zip.ErrFormat) {
+		// archive/zip checks the size itself while reading, it is the only format error it finds then
+		return nil, fmt.Errorf("%w: more than the %d bytes it declares", ErrZipEntryTooLarge, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: more than the %d bytes it declares", ErrZipEntryTooLarge, limit)
+	}
+
+	return content, nil
+}
+
+// WithMaxZipEntrySize limits how many bytes ParseZip extracts from each entry, larger entries fail with ErrZipEntryTooLarge. 0 disables the limit.
+func WithMaxZipEntrySize(maxBytes int64) Option {
+	return func(c *Client) {
+		c.maxZipEntrySize = maxBytes
+	}
+}
@@ -0,0 +1,110 @@
+package llamaparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name    string
+	content string
+	// declared is the uncompressed size the archive claims, 0 for the real one
+	declared uint64
+}
+
+func newZip(t *testing.T, entries ...zipEntry) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, entry := range entries {
+		if entry.declared == 0 {
+			w, err := writer.Create(entry.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(entry.content))
+			continue
+		}
+
+		// stored as is, so the header can claim any size
+		w, err := writer.CreateRaw(&zip.FileHeader{
+			Name:               entry.name,
+			Method:             zip.Store,
+			CompressedSize64:   uint64(len(entry.content)),
+			UncompressedSize64: entry.declared,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entry.content))
+	}
+
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return archive.Bytes()
+}
+
+func TestParseZip(t *testing.T) {
+	server := newFakeServer(t)
+	archive := newZip(t,
+		zipEntry{name: "docs/", content: ""},
+		zipEntry{name: "docs/a.txt", content: "first"},
+		zipEntry{name: "empty.txt", content: ""},
+		zipEntry{name: "b.txt", content: "second"},
+	)
+
+	results, err := server.client().ParseZip(archive, TEXT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0] != (ZipEntryResult{"docs/a.txt", "first"}) || results[1] != (ZipEntryResult{"b.txt", "second"}) {
+		t.Errorf("got %+v, want the two files", results)
+	}
+
+	results, err = server.client(WithAllowEmptyFile(true)).ParseZip(archive, TEXT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[1] != (ZipEntryResult{"empty.txt", ""}) {
+		t.Errorf("got %+v with WithAllowEmptyFile, want the empty file too", results)
+	}
+}
+
+func TestParseZipEntrySize(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry zipEntry
+		opts  []Option
+	}{
+		{
+			name:  "larger than declared",
+			entry: zipEntry{name: "bomb.txt", content: strings.Repeat("x", 1000), declared: 10},
+		},
+		{
+			name:  "larger than the limit",
+			entry: zipEntry{name: "big.txt", content: strings.Repeat("x", 1000)},
+			opts:  []Option{WithMaxZipEntrySize(100)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeServer(t)
+
+			_, err := server.client(test.opts...).ParseZip(newZip(t, test.entry), TEXT)
+			if !errors.Is(err, ErrZipEntryTooLarge) {
+				t.Errorf("got %v, want ErrZipEntryTooLarge", err)
+			}
+			if uploads := server.uploadCount(); uploads != 0 {
+				t.Errorf("got %d uploads, want none", uploads)
+			}
+		})
+	}
+}